@@ -1,6 +1,6 @@
 #!/bin/bash
 
-# Build all three executables
+# Build all executables
 echo "Building openapi-mcp-http..."
 go build -o openapi-mcp-http ./cmd/openapi-mcp-http
 
@@ -10,6 +10,9 @@ go build -o openapi-mcp-interactive ./cmd/openapi-mcp-interactive
 echo "Building openapi-mcp-stdio..."
 go build -o openapi-mcp-stdio ./cmd/openapi-mcp-stdio
 
+echo "Building openapi-mcp-mock..."
+go build -o openapi-mcp-mock ./cmd/openapi-mcp-mock
+
 echo "All builds completed!"
 
 # Optional: build Docker images
@@ -29,9 +32,14 @@ if [ "$1" = "docker" ]; then
     echo "Building interactive version..."
     docker build --build-arg MODE=interactive -t openapi-mcp:interactive .
     
+    # Build mock server version
+    echo "Building mock version..."
+    docker build --build-arg MODE=mock -t openapi-mcp:mock .
+    
     echo "Docker builds completed!"
     echo "Available images:"
     echo "  - openapi-mcp:stdio (also tagged as openapi-mcp:latest)"
     echo "  - openapi-mcp:http"
     echo "  - openapi-mcp:interactive"
+    echo "  - openapi-mcp:mock"
 fi
\ No newline at end of file
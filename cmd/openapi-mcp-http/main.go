@@ -3,7 +3,6 @@ package main
 import (
 	"flag"
 	"log"
-	"os"
 
 	"go_openapi_mcp/internal"
 )
@@ -13,21 +12,38 @@ func main() {
 	flag.StringVar(&addr, "addr", internal.DefaultHTTPPort, "HTTP server address")
 	flag.Parse()
 
-	specSource := os.Getenv("OPENAPI_SPEC_URL")
-	if specSource == "" {
-		log.Fatalf("OPENAPI_SPEC_URL is not specified. Set it to a file path or http/https url")
+	sources, err := internal.GetSpecSources()
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
 
-	// Create OpenAPI server
-	oas := internal.NewOpenAPIServer(specSource, internal.GetCacheDir())
+	download, err := internal.GetDownloadConfig()
+	if err != nil {
+		log.Fatalf("Invalid spec download configuration: %v", err)
+	}
+
+	categories, err := internal.GetCategoryStrategy()
+	if err != nil {
+		log.Fatalf("Invalid category configuration: %v", err)
+	}
+
+	calls, err := internal.GetCallConfig()
+	if err != nil {
+		log.Fatalf("Invalid call_endpoint configuration: %v", err)
+	}
+
+	// Create OpenAPI servers for every configured spec
+	registry := internal.NewSpecRegistry(sources, internal.GetCacheDir(), download)
+	registry.SetCategoryStrategy(categories)
+	registry.SetCallConfig(calls)
 
-	// Load the spec
-	if err := oas.LoadSpec(); err != nil {
+	// Load the specs
+	if err := registry.LoadAll(); err != nil {
 		log.Fatalf("Failed to load OpenAPI spec: %v", err)
 	}
 
 	// Start HTTP server
-	if err := internal.StartHTTPServer(oas, addr); err != nil {
+	if err := internal.StartHTTPServer(registry, addr); err != nil {
 		log.Fatalf("HTTP server error: %v", err)
 	}
 }
\ No newline at end of file
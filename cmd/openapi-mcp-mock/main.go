@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"go_openapi_mcp/internal"
+)
+
+func main() {
+	var addr string
+	flag.StringVar(&addr, "addr", internal.DefaultMockPort, "Mock server address")
+	flag.Parse()
+
+	sources, err := internal.GetSpecSources()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	download, err := internal.GetDownloadConfig()
+	if err != nil {
+		log.Fatalf("Invalid spec download configuration: %v", err)
+	}
+
+	// Create OpenAPI servers for every configured spec
+	registry := internal.NewSpecRegistry(sources, internal.GetCacheDir(), download)
+
+	// Load the specs
+	if err := registry.LoadAll(); err != nil {
+		log.Fatalf("Failed to load OpenAPI spec: %v", err)
+	}
+
+	// Start mock server
+	if err := internal.StartMockServer(registry, addr); err != nil {
+		log.Fatalf("Mock server error: %v", err)
+	}
+}
\ No newline at end of file
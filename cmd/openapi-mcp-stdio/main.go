@@ -1,29 +1,51 @@
 package main
 
 import (
+	"context"
 	"log"
-	"os"
 
 	"github.com/mark3labs/mcp-go/server"
 	"go_openapi_mcp/internal"
 )
 
 func main() {
-	specSource := os.Getenv("OPENAPI_SPEC_URL")
-	if specSource == "" {
-		log.Fatalf("OPENAPI_SPEC_URL is not specified. Set it to a file path or http/https url")
+	sources, err := internal.GetSpecSources()
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
 
-	// Create OpenAPI server
-	oas := internal.NewOpenAPIServer(specSource, internal.GetCacheDir())
+	download, err := internal.GetDownloadConfig()
+	if err != nil {
+		log.Fatalf("Invalid spec download configuration: %v", err)
+	}
+
+	categories, err := internal.GetCategoryStrategy()
+	if err != nil {
+		log.Fatalf("Invalid category configuration: %v", err)
+	}
+
+	calls, err := internal.GetCallConfig()
+	if err != nil {
+		log.Fatalf("Invalid call_endpoint configuration: %v", err)
+	}
+
+	// Create OpenAPI servers for every configured spec
+	registry := internal.NewSpecRegistry(sources, internal.GetCacheDir(), download)
+	registry.SetCategoryStrategy(categories)
+	registry.SetCallConfig(calls)
 
-	// Load the spec
-	if err := oas.LoadSpec(); err != nil {
+	// Load the specs
+	if err := registry.LoadAll(); err != nil {
 		log.Fatalf("Failed to load OpenAPI spec: %v", err)
 	}
 
 	// Create and run MCP server
-	s := internal.CreateMCPServerWithTools(oas)
+	s := internal.CreateMCPServerWithTools(registry)
+
+	// Reload specs in the background when configured
+	if interval := internal.GetReloadInterval(); interval > 0 {
+		go internal.WatchSpecs(context.Background(), registry, s, interval)
+	}
 
 	if err := server.ServeStdio(s); err != nil {
 		log.Fatalf("Server error: %v", err)
@@ -6,17 +6,17 @@ toolchain go1.23.10
 
 require (
 	github.com/getkin/kin-openapi v0.132.0
+	github.com/go-openapi/jsonpointer v0.21.0
 	github.com/mark3labs/mcp-go v0.32.0
+	github.com/oasdiff/yaml v0.0.0-20250309154309-f31be36b4037
 )
 
 require (
-	github.com/go-openapi/jsonpointer v0.21.0 // indirect
 	github.com/go-openapi/swag v0.23.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
-	github.com/oasdiff/yaml v0.0.0-20250309154309-f31be36b4037 // indirect
 	github.com/oasdiff/yaml3 v0.0.0-20250309153720-d2182401db90 // indirect
 	github.com/perimeterx/marshmallow v1.1.5 // indirect
 	github.com/spf13/cast v1.7.1 // indirect
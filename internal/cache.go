@@ -4,12 +4,16 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,23 +23,84 @@ const (
 )
 
 type cacheMetadata struct {
-	URL        string    `json:"url"`
-	CachedAt   time.Time `json:"cached_at"`
-	Expiration time.Time `json:"expiration"`
+	URL          string    `json:"url"`
+	CachedAt     time.Time `json:"cached_at"`
+	Expiration   time.Time `json:"expiration"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+
+	// noStore is set when the response forbids storing it, it is never written to disk
+	noStore bool
 }
 
 type Cache struct {
 	dir string
 	ttl time.Duration
+
+	// maxStale bounds how long past expiration a cached copy may be served when the download fails.
+	// Zero means there is no limit.
+	maxStale time.Duration
+	// offline serves cached copies regardless of age and never touches the network
+	offline bool
+
+	downloadConfig DownloadConfig
+
+	mu     sync.Mutex
+	status map[string]CacheStatus
+}
+
+// CacheStatus describes where the last load of a URL was served from
+type CacheStatus struct {
+	CachedAt   time.Time
+	Expiration time.Time
+	FromCache  bool
+	Stale      bool // served past its expiration because the download failed or offline mode is on
 }
 
 func NewCache(dir string, ttl time.Duration) *Cache {
 	return &Cache{
-		dir: dir,
-		ttl: ttl,
+		dir:    dir,
+		ttl:    ttl,
+		status: map[string]CacheStatus{},
+
+		downloadConfig: DefaultDownloadConfig(),
 	}
 }
 
+// SetDownloadConfig sets the HTTP client and extra request headers used for downloads
+func (c *Cache) SetDownloadConfig(config DownloadConfig) {
+	c.downloadConfig = config
+}
+
+// GetCacheMaxStale reads the maximum staleness of cached specs served when downloads fail
+func GetCacheMaxStale() time.Duration {
+	maxStale, err := time.ParseDuration(os.Getenv("OPENAPI_CACHE_MAX_STALE"))
+	if err != nil || maxStale < 0 {
+		return 0
+	}
+	return maxStale
+}
+
+// GetOfflineMode reports whether remote specs must only be served from the cache
+func GetOfflineMode() bool {
+	offline, _ := strconv.ParseBool(os.Getenv("OPENAPI_OFFLINE"))
+	return offline
+}
+
+// Status returns how the last load of url was served
+func (c *Cache) Status(url string) (CacheStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status, exists := c.status[url]
+	return status, exists
+}
+
+func (c *Cache) setStatus(url string, status CacheStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status[url] = status
+}
+
 func (c *Cache) LoadFromURL(url string) ([]byte, error) {
 	// Create cache directory if it doesn't exist
 	if err := os.MkdirAll(c.dir, CacheDirPerms); err != nil {
@@ -48,78 +113,218 @@ func (c *Cache) LoadFromURL(url string) ([]byte, error) {
 	metaFile := filepath.Join(c.dir, cacheKey+".meta.json")
 
 	// Check if cached version exists and is valid
-	if cachedData, err := c.loadFromCache(cacheFile, metaFile); err == nil {
+	cachedData, meta, cacheErr := c.loadFromCache(cacheFile, metaFile)
+	if cacheErr == nil && time.Now().Before(meta.Expiration) {
 		log.Printf("Using cached OpenAPI spec from %s\n", cacheFile)
+		c.setStatus(url, CacheStatus{CachedAt: meta.CachedAt, Expiration: meta.Expiration, FromCache: true})
 		return cachedData, nil
 	}
 
-	// Download from URL
-	log.Printf("Downloading OpenAPI spec from %s", url)
-	resp, err := http.Get(url)
+	if c.offline {
+		if cacheErr != nil {
+			return nil, fmt.Errorf("offline mode: no cached copy of %s: %w", url, cacheErr)
+		}
+		log.Printf("Offline mode: using cached OpenAPI spec from %s (cached at %s)", cacheFile, meta.CachedAt.Format(time.RFC3339))
+		c.setStatus(url, CacheStatus{CachedAt: meta.CachedAt, Expiration: meta.Expiration, FromCache: true, Stale: true})
+		return cachedData, nil
+	}
+
+	// Revalidate the expired copy if the server gave us validators, otherwise download it again
+	var validators *cacheMetadata
+	if cacheErr == nil {
+		validators = &meta
+	}
+	data, newMeta, notModified, err := c.download(url, validators)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download spec: %w", err)
+		// Fall back to the expired copy rather than failing, as long as it is not too stale
+		if cacheErr == nil && (c.maxStale == 0 || time.Since(meta.Expiration) <= c.maxStale) {
+			log.Printf("Warning: %v; serving stale cached OpenAPI spec from %s (expired %s)", err, cacheFile, meta.Expiration.Format(time.RFC3339))
+			c.setStatus(url, CacheStatus{CachedAt: meta.CachedAt, Expiration: meta.Expiration, FromCache: true, Stale: true})
+			return cachedData, nil
+		}
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to download spec: HTTP %d", resp.StatusCode)
+	// A no-store response is only used for this load and any copy already on disk is dropped
+	if newMeta.noStore {
+		if notModified {
+			data = cachedData
+		}
+		for _, file := range []string{cacheFile, metaFile} {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				log.Printf("Warning: failed to remove cached copy: %v", err)
+			}
+		}
+		log.Printf("Downloaded OpenAPI spec from %s without caching it (Cache-Control: no-store)", url)
+		c.setStatus(url, CacheStatus{CachedAt: newMeta.CachedAt, Expiration: newMeta.Expiration, FromCache: notModified})
+		return data, nil
 	}
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	if notModified {
+		// Keep the cached copy and only extend its lifetime
+		newMeta.CachedAt = meta.CachedAt
+		if err := c.saveMetadata(metaFile, newMeta); err != nil {
+			log.Printf("Warning: failed to save to cache: %v", err)
+		}
+		log.Printf("Cached OpenAPI spec from %s is still up to date", url)
+		c.setStatus(url, CacheStatus{CachedAt: newMeta.CachedAt, Expiration: newMeta.Expiration, FromCache: true})
+		return cachedData, nil
 	}
 
 	// Save to cache
-	if err := c.saveToCache(cacheFile, metaFile, url, data); err != nil {
+	if err := c.saveToCache(cacheFile, metaFile, data, newMeta); err != nil {
 		// Log error but continue - cache is optional
 		log.Printf("Warning: failed to save to cache: %v", err)
 	}
 
 	log.Printf("Downloaded and cached OpenAPI spec from %s", url)
+	c.setStatus(url, CacheStatus{CachedAt: newMeta.CachedAt, Expiration: newMeta.Expiration})
 
 	return data, nil
 }
 
+// download fetches url, sending conditional request headers when validators of a cached copy are
+// given. It reports notModified when the server confirms the cached copy is still current.
+func (c *Cache) download(url string, validators *cacheMetadata) ([]byte, cacheMetadata, bool, error) {
+	meta := cacheMetadata{URL: url}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, meta, false, fmt.Errorf("failed to download spec: %w", err)
+	}
+	// Credentials only go to trusted origins, refs to other hosts are fetched anonymously
+	client := c.downloadConfig.PublicClient
+	if c.downloadConfig.trusts(req.URL) {
+		trusted := *c.downloadConfig.Client
+		trusted.CheckRedirect = func(redirect *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if !c.downloadConfig.trusts(redirect.URL) {
+				return fmt.Errorf("redirect to %s leaves the origins trusted with the download credentials", urlOrigin(redirect.URL))
+			}
+			return nil
+		}
+		client = &trusted
+		for name, values := range c.downloadConfig.Headers {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+	}
+	if validators != nil {
+		if validators.ETag != "" {
+			req.Header.Set("If-None-Match", validators.ETag)
+		}
+		if validators.LastModified != "" {
+			req.Header.Set("If-Modified-Since", validators.LastModified)
+		}
+	}
+
+	// Download from URL
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		log.Printf("Revalidating cached OpenAPI spec from %s", url)
+	} else {
+		log.Printf("Downloading OpenAPI spec from %s", url)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, meta, false, fmt.Errorf("failed to download spec: %w", err)
+	}
+	defer resp.Body.Close()
+
+	now := time.Now()
+	meta.CachedAt = now
+	meta.Expiration = c.expiration(resp.Header, now)
+	meta.ETag = resp.Header.Get("ETag")
+	meta.LastModified = resp.Header.Get("Last-Modified")
+	meta.noStore = hasCacheDirective(resp.Header, "no-store")
+
+	if resp.StatusCode == http.StatusNotModified && validators != nil {
+		// A 304 may omit validators that are still valid
+		if meta.ETag == "" {
+			meta.ETag = validators.ETag
+		}
+		if meta.LastModified == "" {
+			meta.LastModified = validators.LastModified
+		}
+		return nil, meta, true, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, meta, false, fmt.Errorf("failed to download spec: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, meta, false, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return data, meta, false, nil
+}
+
+// expiration honors the Cache-Control max-age of the response, falling back to the cache TTL.
+// no-cache and no-store make the copy expire immediately so it is revalidated on the next load.
+func (c *Cache) expiration(header http.Header, now time.Time) time.Time {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-cache" || directive == "no-store":
+			return now
+		case strings.HasPrefix(directive, "max-age="):
+			if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil && seconds >= 0 {
+				return now.Add(time.Duration(seconds) * time.Second)
+			}
+		}
+	}
+	return now.Add(c.ttl)
+}
+
+// hasCacheDirective reports whether the Cache-Control header of a response carries a directive
+func hasCacheDirective(header http.Header, name string) bool {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), name) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Cache) generateKey(url string) string {
 	hash := sha256.Sum256([]byte(url))
 	return hex.EncodeToString(hash[:])
 }
 
-func (c *Cache) loadFromCache(cacheFile, metaFile string) ([]byte, error) {
+// loadFromCache reads a cached copy and its metadata regardless of expiration
+func (c *Cache) loadFromCache(cacheFile, metaFile string) ([]byte, cacheMetadata, error) {
+	var meta cacheMetadata
+
 	// Read metadata
 	metaData, err := os.ReadFile(metaFile)
 	if err != nil {
-		return nil, err
+		return nil, meta, err
 	}
 
-	var meta cacheMetadata
 	if err := json.Unmarshal(metaData, &meta); err != nil {
-		return nil, err
-	}
-
-	// Check if cache is still valid
-	if time.Now().After(meta.Expiration) {
-		return nil, fmt.Errorf("cache expired")
+		return nil, meta, err
 	}
 
 	// Read cached data
-	return os.ReadFile(cacheFile)
+	data, err := os.ReadFile(cacheFile)
+	return data, meta, err
 }
 
-func (c *Cache) saveToCache(cacheFile, metaFile, url string, data []byte) error {
+func (c *Cache) saveToCache(cacheFile, metaFile string, data []byte, meta cacheMetadata) error {
 	// Save data
 	if err := os.WriteFile(cacheFile, data, CacheFilePerms); err != nil {
 		return err
 	}
 
 	// Save metadata
-	meta := cacheMetadata{
-		URL:        url,
-		CachedAt:   time.Now(),
-		Expiration: time.Now().Add(c.ttl),
-	}
+	return c.saveMetadata(metaFile, meta)
+}
 
+func (c *Cache) saveMetadata(metaFile string, meta cacheMetadata) error {
 	metaData, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
 		return err
@@ -0,0 +1,263 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// specServer serves a spec with the given response headers and records the conditional headers
+// of every request
+type specServer struct {
+	*httptest.Server
+	body        string
+	header      http.Header
+	requests    int
+	conditional []string
+}
+
+func newSpecServer(t *testing.T, body string, header http.Header) *specServer {
+	t.Helper()
+	s := &specServer{body: body, header: header}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		for name, values := range s.header {
+			w.Header()[name] = values
+		}
+		etag, lastModified := s.header.Get("ETag"), s.header.Get("Last-Modified")
+		if match := r.Header.Get("If-None-Match"); match != "" {
+			s.conditional = append(s.conditional, "If-None-Match: "+match)
+			if match == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		if since := r.Header.Get("If-Modified-Since"); since != "" {
+			s.conditional = append(s.conditional, "If-Modified-Since: "+since)
+			if since == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.Write([]byte(s.body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func loadFromCache(t *testing.T, cache *Cache, url string) string {
+	t.Helper()
+	data, err := cache.LoadFromURL(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCacheRevalidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      http.Header
+		conditional string
+	}{
+		{
+			name:        "etag",
+			header:      http.Header{"Etag": {`"v1"`}, "Cache-Control": {"max-age=0"}},
+			conditional: `If-None-Match: "v1"`,
+		},
+		{
+			name:        "last modified",
+			header:      http.Header{"Last-Modified": {"Mon, 15 Jan 2024 09:30:00 GMT"}, "Cache-Control": {"no-cache"}},
+			conditional: "If-Modified-Since: Mon, 15 Jan 2024 09:30:00 GMT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSpecServer(t, "version: 1", tt.header)
+			cache := NewCache(t.TempDir(), time.Hour)
+
+			if got := loadFromCache(t, cache, server.URL); got != "version: 1" {
+				t.Fatalf("first load = %q", got)
+			}
+			if status, _ := cache.Status(server.URL); status.FromCache {
+				t.Fatal("first load reported as served from the cache")
+			}
+
+			// The copy expired immediately, so it is revalidated and the server confirms it
+			if got := loadFromCache(t, cache, server.URL); got != "version: 1" {
+				t.Fatalf("revalidated load = %q", got)
+			}
+			if len(server.conditional) != 1 || server.conditional[0] != tt.conditional {
+				t.Fatalf("conditional headers = %v, want %s", server.conditional, tt.conditional)
+			}
+			if status, _ := cache.Status(server.URL); !status.FromCache {
+				t.Fatal("revalidated load not reported as served from the cache")
+			}
+
+			// A changed spec replaces the cached copy
+			server.body = "version: 2"
+			server.header = http.Header{"Etag": {`"v2"`}, "Cache-Control": {"max-age=0"}}
+			if got := loadFromCache(t, cache, server.URL); got != "version: 2" {
+				t.Fatalf("load after change = %q", got)
+			}
+			if got := loadFromCache(t, cache, server.URL); got != "version: 2" {
+				t.Fatalf("revalidated load after change = %q", got)
+			}
+			if last := server.conditional[len(server.conditional)-1]; last != `If-None-Match: "v2"` {
+				t.Fatalf("last conditional header = %s, want the new ETag", last)
+			}
+		})
+	}
+}
+
+func TestCacheExpiration(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   http.Header
+		requests int
+	}{
+		{name: "max-age", header: http.Header{"Cache-Control": {"public, max-age=3600"}}, requests: 1},
+		{name: "no-store", header: http.Header{"Cache-Control": {"no-store"}}, requests: 3},
+		{name: "no-cache", header: http.Header{"Cache-Control": {"no-cache"}}, requests: 3},
+		{name: "ttl without cache-control", header: http.Header{}, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSpecServer(t, "openapi: 3.0.3", tt.header)
+			cache := NewCache(t.TempDir(), time.Hour)
+
+			for i := 0; i < 3; i++ {
+				loadFromCache(t, cache, server.URL)
+			}
+			if server.requests != tt.requests {
+				t.Fatalf("server saw %d requests, want %d", server.requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestCacheMaxAgeSetsExpiration(t *testing.T) {
+	server := newSpecServer(t, "openapi: 3.0.3", http.Header{"Cache-Control": {"max-age=120"}})
+	cache := NewCache(t.TempDir(), time.Hour)
+
+	before := time.Now()
+	loadFromCache(t, cache, server.URL)
+	status, _ := cache.Status(server.URL)
+	if expires := status.Expiration.Sub(before); expires < 119*time.Second || expires > 121*time.Second {
+		t.Fatalf("expiration %s after the download, want about 2m", expires)
+	}
+}
+
+func TestCacheNoStoreIsNotWrittenToDisk(t *testing.T) {
+	server := newSpecServer(t, "version: 1", http.Header{"Etag": {`"v1"`}, "Cache-Control": {"max-age=0"}})
+	dir := t.TempDir()
+	cache := NewCache(dir, time.Hour)
+	loadFromCache(t, cache, server.URL)
+	if entries, _ := os.ReadDir(dir); len(entries) == 0 {
+		t.Fatal("a cacheable response was not written to disk")
+	}
+
+	// Once the server forbids storing the spec, the copy on disk is dropped too
+	server.body = "version: 2"
+	server.header = http.Header{"Etag": {`"v2"`}, "Cache-Control": {"no-store"}}
+	for i := 0; i < 2; i++ {
+		if got := loadFromCache(t, cache, server.URL); got != "version: 2" {
+			t.Fatalf("load %d = %q", i, got)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Fatalf("no-store response left %d files in the cache directory", len(entries))
+		}
+	}
+	if server.requests != 3 {
+		t.Fatalf("server saw %d requests, want every no-store load to download", server.requests)
+	}
+	if len(server.conditional) != 1 {
+		t.Fatalf("conditional headers = %v, a no-store copy must not be revalidated", server.conditional)
+	}
+}
+
+func TestCacheServesStaleCopyWhenDownloadFails(t *testing.T) {
+	server := newSpecServer(t, "version: 1", http.Header{"Cache-Control": {"max-age=0"}})
+	cache := NewCache(t.TempDir(), time.Hour)
+	loadFromCache(t, cache, server.URL)
+	server.Close()
+
+	// The copy expired right away and the origin is gone, so the expired copy is served
+	if got := loadFromCache(t, cache, server.URL); got != "version: 1" {
+		t.Fatalf("stale load = %q", got)
+	}
+	if status, _ := cache.Status(server.URL); !status.FromCache || !status.Stale {
+		t.Fatalf("stale load reported as %+v", status)
+	}
+
+	cache.maxStale = time.Hour
+	if got := loadFromCache(t, cache, server.URL); got != "version: 1" {
+		t.Fatalf("load within max-stale = %q", got)
+	}
+
+	// Past OPENAPI_CACHE_MAX_STALE the download error is returned instead
+	cache.maxStale = time.Nanosecond
+	if _, err := cache.LoadFromURL(server.URL); err == nil || !strings.Contains(err.Error(), "failed to download spec") {
+		t.Fatalf("copy older than max-stale was served, err = %v", err)
+	}
+}
+
+func TestCacheOffline(t *testing.T) {
+	server := newSpecServer(t, "version: 1", http.Header{"Cache-Control": {"max-age=0"}})
+	dir := t.TempDir()
+	loadFromCache(t, NewCache(dir, time.Hour), server.URL)
+
+	// An expired copy is served without contacting the origin
+	cache := NewCache(dir, time.Hour)
+	cache.offline = true
+	if got := loadFromCache(t, cache, server.URL); got != "version: 1" {
+		t.Fatalf("offline load = %q", got)
+	}
+	if server.requests != 1 {
+		t.Fatalf("server saw %d requests in offline mode, want only the first download", server.requests)
+	}
+	if status, _ := cache.Status(server.URL); !status.FromCache || !status.Stale {
+		t.Fatalf("offline load reported as %+v", status)
+	}
+
+	// Without a cached copy there is nothing to serve
+	empty := NewCache(t.TempDir(), time.Hour)
+	empty.offline = true
+	if _, err := empty.LoadFromURL(server.URL); err == nil || !strings.Contains(err.Error(), "offline mode: no cached copy") {
+		t.Fatalf("offline load without a cached copy, err = %v", err)
+	}
+	if server.requests != 1 {
+		t.Fatalf("server saw %d requests in offline mode, want only the first download", server.requests)
+	}
+}
+
+func TestSpecInfoReportsStaleCache(t *testing.T) {
+	server := newSpecServer(t, `
+openapi: 3.0.3
+info: {title: stale, version: "1"}
+paths: {}
+`, http.Header{"Cache-Control": {"max-age=0"}})
+	oas := NewOpenAPIServer(server.URL, t.TempDir())
+	if err := oas.LoadSpec(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	if err := oas.LoadSpec(); err != nil {
+		t.Fatalf("reload with the origin down failed: %v", err)
+	}
+
+	var info struct {
+		Cache struct {
+			Stale bool `json:"stale"`
+		} `json:"cache"`
+	}
+	callToolJSON(t, oas.getSpecInfoHandler, map[string]interface{}{}, &info)
+	if !info.Cache.Stale {
+		t.Fatal("get_spec_info does not report the stale cached copy")
+	}
+}
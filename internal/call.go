@@ -0,0 +1,350 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	DefaultCallTimeout = 30 * time.Second
+	// MaxCallResponseBytes caps how much of a response body is read and returned
+	MaxCallResponseBytes = 1 << 20
+)
+
+// CallConfig controls the call_endpoint tool, which sends real requests to the API
+type CallConfig struct {
+	Enabled bool
+	// BaseURL replaces the spec's servers when set
+	BaseURL string
+	// Methods are the HTTP methods that may be sent
+	Methods []string
+	// Hosts are the hosts requests may go to, as host or host:port. When empty, the host of
+	// BaseURL or else the hosts of the spec's servers are allowed. The spec's servers are never
+	// trusted with credentials.
+	Hosts []string
+	// DryRun prepares requests without sending them
+	DryRun bool
+	Client *http.Client
+	// Credentials returns the value of a credential variable such as
+	// OPENAPI_CRED_PETSTORE__BEARER_AUTH_TOKEN. It is only asked for the variables of the spec
+	// being called, see credentialPrefix.
+	Credentials func(variable string) string
+}
+
+// DefaultCallConfig leaves calls disabled and only allows GET requests once enabled
+func DefaultCallConfig() CallConfig {
+	return CallConfig{
+		Methods:     []string{"GET"},
+		Client:      &http.Client{Timeout: DefaultCallTimeout},
+		Credentials: os.Getenv,
+	}
+}
+
+// GetCallConfig reads the call_endpoint settings from the environment
+func GetCallConfig() (CallConfig, error) {
+	config := DefaultCallConfig()
+	config.Enabled, _ = strconv.ParseBool(os.Getenv("OPENAPI_CALLS_ENABLED"))
+	config.DryRun, _ = strconv.ParseBool(os.Getenv("OPENAPI_CALL_DRY_RUN"))
+
+	if baseURL := os.Getenv("OPENAPI_CALL_BASE_URL"); baseURL != "" {
+		if !isURL(baseURL) {
+			return config, fmt.Errorf("invalid OPENAPI_CALL_BASE_URL %q, expected an http or https URL", baseURL)
+		}
+		config.BaseURL = baseURL
+	}
+
+	if methods := os.Getenv("OPENAPI_CALL_METHODS"); methods != "" {
+		config.Methods = nil
+		for _, method := range strings.Split(methods, ",") {
+			if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+				config.Methods = append(config.Methods, method)
+			}
+		}
+	}
+
+	if hosts := os.Getenv("OPENAPI_CALL_HOSTS"); hosts != "" {
+		for _, host := range strings.Split(hosts, ",") {
+			if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+				config.Hosts = append(config.Hosts, host)
+			}
+		}
+	}
+
+	if timeout := os.Getenv("OPENAPI_CALL_TIMEOUT"); timeout != "" {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil || duration <= 0 {
+			return config, fmt.Errorf("invalid OPENAPI_CALL_TIMEOUT: %s", timeout)
+		}
+		config.Client.Timeout = duration
+	}
+
+	return config, nil
+}
+
+// hostsConfigured reports whether the allowed hosts come from the configuration rather than from
+// the spec's servers
+func (oas *OpenAPIServer) hostsConfigured() bool {
+	return len(oas.calls.Hosts) > 0 || oas.calls.BaseURL != ""
+}
+
+// credentials looks up credential variables, limited to those of this spec so a spec cannot read
+// other environment variables or the credentials of another spec
+func (oas *OpenAPIServer) credentials(variable string) string {
+	if !strings.HasPrefix(variable, credentialPrefix(oas.name)) {
+		return ""
+	}
+	return oas.calls.Credentials(variable)
+}
+
+// allowedHosts returns the hosts calls may be sent to
+func (oas *OpenAPIServer) allowedHosts() []string {
+	if len(oas.calls.Hosts) > 0 {
+		return oas.calls.Hosts
+	}
+	if oas.calls.BaseURL != "" {
+		if u, err := url.Parse(oas.calls.BaseURL); err == nil {
+			return []string{strings.ToLower(u.Host)}
+		}
+		return nil
+	}
+
+	hosts := []string{}
+	for _, server := range allServers(oas.spec) {
+		serverURL := server.URL
+		for name, variable := range server.Variables {
+			serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variable.Default)
+		}
+		// Relative servers are on the host the spec was downloaded from
+		if !isURL(serverURL) {
+			if !isURL(oas.specSource) {
+				continue
+			}
+			serverURL = oas.specSource
+		}
+		if u, err := url.Parse(serverURL); err == nil && !slices.Contains(hosts, strings.ToLower(u.Host)) {
+			hosts = append(hosts, strings.ToLower(u.Host))
+		}
+	}
+	return hosts
+}
+
+// hostAllowed checks a URL against the host allowlist, which may name a host with or without port
+func hostAllowed(u *url.URL, allowed []string) bool {
+	host := strings.ToLower(u.Host)
+	return slices.Contains(allowed, host) || slices.Contains(allowed, strings.ToLower(u.Hostname()))
+}
+
+// endpointCall is a request prepared under the spec lock, with the parts of the spec its response
+// is validated against
+type endpointCall struct {
+	spec      *openapi3.T
+	path      string
+	pathItem  *openapi3.PathItem
+	operation *openapi3.Operation
+	req       *http.Request
+	allowed   []string
+	result    map[string]interface{}
+}
+
+func (oas *OpenAPIServer) callEndpointHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// The spec lock is only held while preparing, a slow API must not hold up reloads and with
+	// them every other tool call on the spec
+	call, done, err := oas.prepareCall(ctx, request)
+	if call == nil {
+		return done, err
+	}
+	result := call.result
+
+	// Redirects must stay on allowed hosts too
+	client := *oas.calls.Client
+	client.CheckRedirect = func(redirect *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if !hostAllowed(redirect.URL, call.allowed) {
+			return fmt.Errorf("redirect to %s is not allowed", redirect.URL.Host)
+		}
+		return nil
+	}
+
+	start := time.Now()
+	resp, err := client.Do(call.req)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Request failed: %v", err)), nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxCallResponseBytes+1))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to read response: %v", err)), nil
+	}
+	truncated := len(body) > MaxCallResponseBytes
+	if truncated {
+		body = body[:MaxCallResponseBytes]
+	}
+
+	responseHeaders := map[string]string{}
+	for name, values := range resp.Header {
+		responseHeaders[name] = strings.Join(values, ", ")
+	}
+	response := map[string]interface{}{
+		"status":      resp.StatusCode,
+		"headers":     responseHeaders,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	var parsed interface{}
+	if isJSONMediaType(resp.Header.Get("Content-Type")) && json.Unmarshal(body, &parsed) == nil {
+		response["body"] = parsed
+	} else if len(body) > 0 {
+		response["body"] = string(body)
+	}
+	if truncated {
+		response["truncated"] = true
+	}
+	result["sent"] = true
+	result["response"] = response
+
+	// A truncated body cannot be checked. The response is checked against the spec the request
+	// was prepared from, even if it was reloaded in the meantime.
+	if !truncated {
+		check := validateResponse(ctx, call.spec, call.path, call.pathItem, call.operation, call.req, resp.StatusCode, resp.Header, body)
+		validation := map[string]interface{}{
+			"valid":      len(check.Violations) == 0,
+			"violations": check.Violations,
+		}
+		if check.Response != "" {
+			validation["response"] = check.Response
+		}
+		if len(check.Undocumented) > 0 {
+			validation["undocumented_fields"] = check.Undocumented
+		}
+		result["validation"] = validation
+	}
+
+	return JSONResponse(result)
+}
+
+// prepareCall builds and checks the request while holding the spec lock. It returns the call to
+// send, or the tool result when the request is refused or not sent.
+func (oas *OpenAPIServer) prepareCall(ctx context.Context, request mcp.CallToolRequest) (*endpointCall, *mcp.CallToolResult, error) {
+	oas.mu.RLock()
+	defer oas.mu.RUnlock()
+
+	if !oas.calls.Enabled {
+		return nil, mcp.NewToolResultError("Calling endpoints is disabled. Set OPENAPI_CALLS_ENABLED=true to enable it"), nil
+	}
+
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, mcp.NewToolResultError(err.Error()), nil
+	}
+
+	method, err := request.RequireString("method")
+	if err != nil {
+		return nil, mcp.NewToolResultError(err.Error()), nil
+	}
+	method = strings.ToUpper(method)
+	if !slices.Contains(oas.calls.Methods, method) {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("Method %s is not allowed. Allowed methods: %s (see OPENAPI_CALL_METHODS)", method, strings.Join(oas.calls.Methods, ", "))), nil
+	}
+
+	pathItem, operation, err := oas.findOperation(path, method)
+	if err != nil {
+		return nil, mcp.NewToolResultError(err.Error()), nil
+	}
+
+	input, err := requestInputFromArguments(request)
+	if err != nil {
+		return nil, mcp.NewToolResultError(err.Error()), nil
+	}
+	// Live calls only carry the caller's values, never made up examples
+	input.NoExamples = true
+	if input.Server == "" {
+		input.Server = oas.calls.BaseURL
+	}
+
+	prepared, err := oas.buildRequest(path, method, pathItem, operation, input)
+	if err != nil {
+		return nil, mcp.NewToolResultError(err.Error()), nil
+	}
+
+	u, err := url.Parse(prepared.URL.String())
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("Invalid request URL: %v", err)), nil
+	}
+	allowed := oas.allowedHosts()
+	if !hostAllowed(u, allowed) {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("Host %s is not allowed. Allowed hosts: %s (see OPENAPI_CALL_HOSTS)", u.Host, strings.Join(allowed, ", "))), nil
+	}
+
+	warnings := prepared.Warnings
+	injected := false
+	for _, credential := range prepared.Credentials {
+		if oas.credentials(credential.Variable) == "" {
+			warnings = append(warnings, fmt.Sprintf("%s is not set, the %s credentials are sent empty", credential.Variable, credential.Scheme))
+		} else {
+			injected = true
+		}
+	}
+	// The spec's servers are chosen by whoever wrote the spec, so credentials only go to hosts
+	// the configuration names
+	if injected && !oas.hostsConfigured() {
+		return nil, mcp.NewToolResultError("Credentials are only sent to hosts named in OPENAPI_CALL_HOSTS or OPENAPI_CALL_BASE_URL, set one of them to call this endpoint"), nil
+	}
+
+	// Credentials are shown as their placeholders
+	result := map[string]interface{}{
+		"request": map[string]interface{}{
+			"method":  prepared.Method,
+			"url":     prepared.URL.String(),
+			"headers": prepared.headerMap(),
+			"curl":    prepared.curlCommand(),
+		},
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+
+	req, err := prepared.httpRequest(ctx, oas.credentials)
+	if err != nil {
+		return nil, mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Requests the spec does not allow are not sent
+	if violations := oas.validateRequest(ctx, path, pathItem, operation, req, prepared.PathParams, input); len(violations) > 0 {
+		result["sent"] = false
+		result["request_violations"] = violations
+		done, err := JSONResponse(result)
+		return nil, done, err
+	}
+
+	if oas.calls.DryRun || request.GetBool("dry_run", false) {
+		result["sent"] = false
+		result["dry_run"] = true
+		done, err := JSONResponse(result)
+		return nil, done, err
+	}
+
+	return &endpointCall{
+		spec:      oas.spec,
+		path:      path,
+		pathItem:  pathItem,
+		operation: operation,
+		req:       req,
+		allowed:   allowed,
+		result:    result,
+	}, nil, nil
+}
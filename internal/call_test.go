@@ -0,0 +1,319 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// apiServer stands in for the API behind a spec and records the requests it receives
+type apiServer struct {
+	*httptest.Server
+	requests []*http.Request
+}
+
+func newAPIServer(t *testing.T, handler http.HandlerFunc) *apiServer {
+	t.Helper()
+	s := &apiServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests = append(s.requests, r)
+		handler(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// host returns the host:port of the server
+func (s *apiServer) host() string {
+	u, _ := url.Parse(s.URL)
+	return u.Host
+}
+
+// loadCallSpec loads a spec whose servers point at serverURL, with calls enabled and every
+// credential variable set
+func loadCallSpec(t *testing.T, serverURL string) *OpenAPIServer {
+	t.Helper()
+	oas := loadTestSpec(t, fmt.Sprintf(`
+openapi: 3.0.3
+info: {title: calls, version: "1"}
+servers: [{url: %q}]
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  required: [name]
+                  properties:
+                    name: {type: string}
+    post:
+      responses: {"201": {description: created}}
+  /private:
+    get:
+      security: [{tokenAuth: []}]
+      responses: {"200": {description: ok}}
+components:
+  securitySchemes:
+    tokenAuth: {type: http, scheme: bearer}
+`, serverURL))
+	oas.calls.Enabled = true
+	oas.calls.Credentials = func(variable string) string { return "secret-" + variable }
+	return oas
+}
+
+func callEndpoint(t *testing.T, oas *OpenAPIServer, method, path string) (map[string]interface{}, string) {
+	t.Helper()
+	text, isError := callTool(t, oas.callEndpointHandler, map[string]interface{}{"method": method, "path": path})
+	if isError {
+		return nil, text
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, text)
+	}
+	return result, ""
+}
+
+func TestCallEndpointMethods(t *testing.T) {
+	api := newAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	oas := loadCallSpec(t, api.URL)
+
+	if _, failure := callEndpoint(t, oas, "post", "/pets"); !strings.Contains(failure, "Method POST is not allowed") {
+		t.Fatalf("POST was not refused by the default GET-only allowlist: %s", failure)
+	}
+	if len(api.requests) != 0 {
+		t.Fatalf("a refused method reached the API %d times", len(api.requests))
+	}
+
+	oas.calls.Methods = []string{"GET", "POST"}
+	if result, failure := callEndpoint(t, oas, "post", "/pets"); failure != "" || result["sent"] != true {
+		t.Fatalf("POST was not sent once allowed: %s %v", failure, result)
+	}
+}
+
+func TestCallEndpointHosts(t *testing.T) {
+	api := newAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	other := newAPIServer(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	// Without configured hosts, the spec's servers are allowed
+	oas := loadCallSpec(t, api.URL)
+	if result, failure := callEndpoint(t, oas, "get", "/pets"); failure != "" || result["sent"] != true {
+		t.Fatalf("call to the spec's server failed: %s %v", failure, result)
+	}
+
+	oas.calls.Hosts = []string{other.host()}
+	if _, failure := callEndpoint(t, oas, "get", "/pets"); !strings.Contains(failure, "Host "+api.host()+" is not allowed") {
+		t.Fatalf("call to a host outside OPENAPI_CALL_HOSTS was not refused: %s", failure)
+	}
+	if len(api.requests) != 1 {
+		t.Fatalf("the API received %d requests, want only the allowed one", len(api.requests))
+	}
+}
+
+func TestCallEndpointRedirects(t *testing.T) {
+	other := newAPIServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	api := newAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+r.URL.Path, http.StatusFound)
+	})
+	oas := loadCallSpec(t, api.URL)
+	oas.calls.Hosts = []string{api.host()}
+
+	if _, failure := callEndpoint(t, oas, "get", "/pets"); !strings.Contains(failure, "redirect to "+other.host()+" is not allowed") {
+		t.Fatalf("redirect to a host that is not allowed was followed: %s", failure)
+	}
+	if len(other.requests) != 0 {
+		t.Fatalf("the redirect target received %d requests", len(other.requests))
+	}
+
+	oas.calls.Hosts = []string{api.host(), other.host()}
+	if result, failure := callEndpoint(t, oas, "get", "/pets"); failure != "" || result["sent"] != true {
+		t.Fatalf("redirect to an allowed host failed: %s %v", failure, result)
+	}
+	if len(other.requests) != 1 {
+		t.Fatalf("the redirect target received %d requests, want 1", len(other.requests))
+	}
+}
+
+func TestCallEndpointCredentials(t *testing.T) {
+	api := newAPIServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	oas := loadCallSpec(t, api.URL)
+	asked := []string{}
+	oas.calls.Credentials = func(variable string) string {
+		asked = append(asked, variable)
+		return "secret"
+	}
+
+	// The spec's servers are not trusted with credentials
+	if _, failure := callEndpoint(t, oas, "get", "/private"); !strings.Contains(failure, "OPENAPI_CALL_HOSTS") {
+		t.Fatalf("credentials were sent to a host chosen by the spec: %s", failure)
+	}
+	if len(api.requests) != 0 {
+		t.Fatalf("the API received %d requests", len(api.requests))
+	}
+
+	oas.calls.Hosts = []string{api.host()}
+	if result, failure := callEndpoint(t, oas, "get", "/private"); failure != "" || result["sent"] != true {
+		t.Fatalf("call with credentials failed: %s %v", failure, result)
+	}
+	if got := api.requests[0].Header.Get("Authorization"); got != "Bearer secret" {
+		t.Fatalf("Authorization header is %q", got)
+	}
+	for _, variable := range asked {
+		if variable != "OPENAPI_CRED_DEFAULT__TOKEN_AUTH_TOKEN" {
+			t.Errorf("credential variable %s outside the spec's prefix was read", variable)
+		}
+	}
+
+	// Placeholders outside the spec's prefix are never looked up
+	asked = nil
+	if value := oas.credentials("HOME"); value != "" || len(asked) != 0 {
+		t.Fatalf("HOME was read as a credential: %q", value)
+	}
+	if value := oas.credentials("OPENAPI_CRED_OTHER__TOKEN_AUTH_TOKEN"); value != "" || len(asked) != 0 {
+		t.Fatalf("the credentials of another spec were read: %q", value)
+	}
+}
+
+func TestCredentialVariable(t *testing.T) {
+	tests := []struct {
+		spec, scheme, suffix, want string
+	}{
+		{"default", "bearerAuth", "TOKEN", "OPENAPI_CRED_DEFAULT__BEARER_AUTH_TOKEN"},
+		{"pet-store", "api_key", "", "OPENAPI_CRED_PET_STORE__API_KEY"},
+		{"a", "b__c", "", "OPENAPI_CRED_A__B_C"},
+		{"a__b", "c", "", "OPENAPI_CRED_A_B__C"},
+	}
+	for _, test := range tests {
+		if got := credentialVariable(test.spec, test.scheme, test.suffix); got != test.want {
+			t.Errorf("credentialVariable(%q, %q, %q) = %s, want %s", test.spec, test.scheme, test.suffix, got, test.want)
+		}
+	}
+}
+
+func TestCallEndpointDryRun(t *testing.T) {
+	api := newAPIServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	oas := loadCallSpec(t, api.URL)
+
+	var result map[string]interface{}
+	callToolJSON(t, oas.callEndpointHandler, map[string]interface{}{"method": "get", "path": "/pets", "dry_run": true}, &result)
+	if result["sent"] != false || result["dry_run"] != true {
+		t.Fatalf("dry run result: %v", result)
+	}
+
+	oas.calls.DryRun = true
+	callToolJSON(t, oas.callEndpointHandler, map[string]interface{}{"method": "get", "path": "/pets"}, &result)
+	if result["sent"] != false || result["dry_run"] != true {
+		t.Fatalf("OPENAPI_CALL_DRY_RUN result: %v", result)
+	}
+	if len(api.requests) != 0 {
+		t.Fatalf("dry runs sent %d requests", len(api.requests))
+	}
+}
+
+func TestCallEndpointResponseCap(t *testing.T) {
+	api := newAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strings.Repeat("x", 2*MaxCallResponseBytes)))
+	})
+	oas := loadCallSpec(t, api.URL)
+
+	result, failure := callEndpoint(t, oas, "get", "/pets")
+	if failure != "" {
+		t.Fatal(failure)
+	}
+	response := result["response"].(map[string]interface{})
+	if body := response["body"].(string); len(body) != MaxCallResponseBytes {
+		t.Fatalf("body has %d bytes, want %d", len(body), MaxCallResponseBytes)
+	}
+	if response["truncated"] != true {
+		t.Fatalf("truncated body is not flagged: %v", response["truncated"])
+	}
+	if _, validated := result["validation"]; validated {
+		t.Fatal("a truncated body was validated")
+	}
+}
+
+func TestCallEndpointValidation(t *testing.T) {
+	body := `[{"name": "Rex"}]`
+	api := newAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+	oas := loadCallSpec(t, api.URL)
+
+	result, failure := callEndpoint(t, oas, "get", "/pets")
+	if failure != "" {
+		t.Fatal(failure)
+	}
+	validation := result["validation"].(map[string]interface{})
+	if validation["valid"] != true || validation["response"] != "200" {
+		t.Fatalf("valid response was reported as %v", validation)
+	}
+
+	body = `[{"name": 7}, {}]`
+	result, failure = callEndpoint(t, oas, "get", "/pets")
+	if failure != "" {
+		t.Fatal(failure)
+	}
+	validation = result["validation"].(map[string]interface{})
+	violations, _ := validation["violations"].([]interface{})
+	if validation["valid"] != false || len(violations) == 0 {
+		t.Fatalf("invalid response was reported as %v", validation)
+	}
+}
+
+func TestCallEndpointReleasesSpecLockWhileSending(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	api := newAPIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"name": "rex"}]`))
+	})
+	oas := loadCallSpec(t, api.URL)
+
+	done := make(chan map[string]interface{})
+	go func() {
+		result, _ := callEndpoint(t, oas, "get", "/pets")
+		done <- result
+	}()
+	<-received
+
+	// A reload can swap the spec while the API is still answering
+	locked := make(chan struct{})
+	go func() {
+		oas.mu.Lock()
+		oas.spec = nil
+		oas.mu.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the spec lock was held while waiting for the API")
+	}
+	close(release)
+
+	// The response is still validated against the spec the request was prepared from
+	result := <-done
+	if validation, _ := result["validation"].(map[string]interface{}); validation["valid"] != true {
+		t.Fatalf("response was not validated against the prepared spec: %v", result)
+	}
+}
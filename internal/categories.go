@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	CategoryModeSegment = "segment" // first N path segments
+	CategoryModeTag     = "tag"     // OpenAPI operation tags
+	CategoryModePrefix  = "prefix"  // first path segment after a common prefix
+
+	UntaggedCategory = "untagged"
+)
+
+// CategoryStrategy decides how endpoints are grouped into categories
+type CategoryStrategy struct {
+	Mode   string
+	Depth  int    // number of segments used by CategoryModeSegment
+	Prefix string // prefix stripped by CategoryModePrefix, e.g. /api/v1
+}
+
+// DefaultCategoryStrategy groups endpoints by their first path segment
+func DefaultCategoryStrategy() CategoryStrategy {
+	return CategoryStrategy{Mode: CategoryModeSegment, Depth: 1}
+}
+
+// GetCategoryStrategy reads the grouping strategy from the environment, defaulting to the first path segment
+func GetCategoryStrategy() (CategoryStrategy, error) {
+	strategy := DefaultCategoryStrategy()
+	strategy.Prefix = os.Getenv("OPENAPI_CATEGORY_PREFIX")
+
+	if mode := strings.ToLower(os.Getenv("OPENAPI_CATEGORY_MODE")); mode != "" {
+		switch mode {
+		case CategoryModeSegment, CategoryModeTag, CategoryModePrefix:
+			strategy.Mode = mode
+		default:
+			return strategy, fmt.Errorf("invalid OPENAPI_CATEGORY_MODE %q, expected %s, %s or %s", mode, CategoryModeSegment, CategoryModeTag, CategoryModePrefix)
+		}
+	}
+	if depth := os.Getenv("OPENAPI_CATEGORY_DEPTH"); depth != "" {
+		value, err := strconv.Atoi(depth)
+		if err != nil || value <= 0 {
+			return strategy, fmt.Errorf("invalid OPENAPI_CATEGORY_DEPTH: %s", depth)
+		}
+		strategy.Depth = value
+	}
+	if strategy.Mode == CategoryModePrefix && strings.Trim(strategy.Prefix, "/") == "" {
+		return strategy, fmt.Errorf("OPENAPI_CATEGORY_PREFIX is required with OPENAPI_CATEGORY_MODE=%s, e.g. /api/v1", CategoryModePrefix)
+	}
+
+	return strategy, nil
+}
+
+// Categories returns the categories an operation belongs to. Only the tag mode can place an
+// operation in more than one category.
+func (cs CategoryStrategy) Categories(path string, operation *openapi3.Operation) []string {
+	switch cs.Mode {
+	case CategoryModeTag:
+		if len(operation.Tags) == 0 {
+			return []string{UntaggedCategory}
+		}
+		return operation.Tags
+	case CategoryModePrefix:
+		prefix := "/" + strings.Trim(cs.Prefix, "/")
+		if prefix != "/" && strings.HasPrefix(path, prefix+"/") {
+			path = strings.TrimPrefix(path, prefix)
+		}
+		return pathCategory(path, 1)
+	default:
+		return pathCategory(path, cs.Depth)
+	}
+}
+
+// Description explains the active strategy for tool descriptions
+func (cs CategoryStrategy) Description() string {
+	switch cs.Mode {
+	case CategoryModeTag:
+		return "the OpenAPI tags of each endpoint"
+	case CategoryModePrefix:
+		return "the first path segment after " + cs.Prefix
+	default:
+		if cs.Depth > 1 {
+			return "the first " + strconv.Itoa(cs.Depth) + " path segments"
+		}
+		return "the first path segment"
+	}
+}
+
+// pathCategory joins up to depth leading path segments, stopping at the first path parameter
+func pathCategory(path string, depth int) []string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	parts := []string{}
+
+	for _, segment := range segments {
+		if len(parts) == depth {
+			break
+		}
+		// Extract the base category (remove any path parameters)
+		segment = strings.Split(segment, "{")[0]
+		if segment == "" {
+			break
+		}
+		parts = append(parts, segment)
+	}
+
+	if len(parts) == 0 {
+		return nil
+	}
+	return []string{strings.Join(parts, "/")}
+}
+
+// tagDescription returns the description of a tag declared in the spec
+func tagDescription(spec *openapi3.T, name string) string {
+	if tag := spec.Tags.Get(name); tag != nil {
+		return tag.Description
+	}
+	return ""
+}
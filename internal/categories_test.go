@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const categoriesSpec = `
+openapi: 3.0.3
+info: {title: categories, version: "1"}
+tags:
+  - {name: pets, description: Everything about pets}
+paths:
+  /api/v1/pets:
+    get: {tags: [pets], responses: {"200": {description: ok}}}
+    post: {tags: [pets, admin], responses: {"201": {description: created}}}
+  /api/v1/pets/{id}:
+    get: {tags: [pets], responses: {"200": {description: ok}}}
+  /api/v1/stores/orders:
+    get: {responses: {"200": {description: ok}}}
+  /api/v2/stores:
+    get: {tags: [stores], responses: {"200": {description: ok}}}
+`
+
+type listedCategory struct {
+	Name          string `json:"name"`
+	EndpointCount int    `json:"endpoint_count"`
+	Description   string `json:"description"`
+}
+
+func TestCategoryStrategies(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategy   CategoryStrategy
+		categories []listedCategory
+		filter     string
+		endpoints  []listedEndpoint
+	}{
+		{
+			name:     "tag",
+			strategy: CategoryStrategy{Mode: CategoryModeTag},
+			categories: []listedCategory{
+				{Name: "admin", EndpointCount: 1},
+				{Name: "pets", EndpointCount: 2, Description: "Everything about pets"},
+				{Name: "stores", EndpointCount: 1},
+				{Name: UntaggedCategory, EndpointCount: 1},
+			},
+			filter:    "admin",
+			endpoints: []listedEndpoint{{"/api/v1/pets", "POST"}},
+		},
+		{
+			name:     "segments",
+			strategy: CategoryStrategy{Mode: CategoryModeSegment, Depth: 3},
+			categories: []listedCategory{
+				{Name: "api/v1/pets", EndpointCount: 2},
+				{Name: "api/v1/stores", EndpointCount: 1},
+				{Name: "api/v2/stores", EndpointCount: 1},
+			},
+			filter:    "api/v1/pets",
+			endpoints: []listedEndpoint{{"/api/v1/pets", "GET"}, {"/api/v1/pets", "POST"}, {"/api/v1/pets/{id}", "GET"}},
+		},
+		{
+			name:     "prefix",
+			strategy: CategoryStrategy{Mode: CategoryModePrefix, Prefix: "/api/v1/"},
+			categories: []listedCategory{
+				{Name: "api", EndpointCount: 1},
+				{Name: "pets", EndpointCount: 2},
+				{Name: "stores", EndpointCount: 1},
+			},
+			filter:    "stores",
+			endpoints: []listedEndpoint{{"/api/v1/stores/orders", "GET"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oas := loadTestSpec(t, categoriesSpec)
+			oas.categoryStrategy = tt.strategy
+
+			var categories []listedCategory
+			callToolJSON(t, oas.listCategoriesHandler, nil, &categories)
+			if !reflect.DeepEqual(categories, tt.categories) {
+				t.Errorf("categories = %+v, want %+v", categories, tt.categories)
+			}
+
+			var page endpointPage
+			callToolJSON(t, oas.listEndpointsHandler, map[string]interface{}{"category": tt.filter}, &page)
+			if !reflect.DeepEqual(page.Items, tt.endpoints) {
+				t.Errorf("endpoints of %s = %v, want %v", tt.filter, page.Items, tt.endpoints)
+			}
+		})
+	}
+}
+
+func TestGetCategoryStrategy(t *testing.T) {
+	t.Setenv("OPENAPI_CATEGORY_MODE", "Tag")
+	t.Setenv("OPENAPI_CATEGORY_DEPTH", "")
+	t.Setenv("OPENAPI_CATEGORY_PREFIX", "")
+	if strategy, err := GetCategoryStrategy(); err != nil || strategy.Mode != CategoryModeTag {
+		t.Fatalf("strategy = %+v, err = %v", strategy, err)
+	}
+
+	for name, tt := range map[string]struct {
+		env   map[string]string
+		names string
+	}{
+		"unknown mode":   {map[string]string{"OPENAPI_CATEGORY_MODE": "tags"}, "OPENAPI_CATEGORY_MODE"},
+		"invalid depth":  {map[string]string{"OPENAPI_CATEGORY_DEPTH": "two"}, "OPENAPI_CATEGORY_DEPTH"},
+		"negative depth": {map[string]string{"OPENAPI_CATEGORY_DEPTH": "-1"}, "OPENAPI_CATEGORY_DEPTH"},
+		"no prefix":      {map[string]string{"OPENAPI_CATEGORY_MODE": "prefix"}, "OPENAPI_CATEGORY_PREFIX"},
+		"root prefix":    {map[string]string{"OPENAPI_CATEGORY_MODE": "prefix", "OPENAPI_CATEGORY_PREFIX": "/"}, "OPENAPI_CATEGORY_PREFIX"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("OPENAPI_CATEGORY_MODE", "")
+			for variable, value := range tt.env {
+				t.Setenv(variable, value)
+			}
+			if _, err := GetCategoryStrategy(); err == nil || !strings.Contains(err.Error(), tt.names) {
+				t.Fatalf("error = %v, want it to name %s", err, tt.names)
+			}
+		})
+	}
+
+	t.Setenv("OPENAPI_CATEGORY_MODE", "prefix")
+	t.Setenv("OPENAPI_CATEGORY_PREFIX", "/api/v1")
+	if strategy, err := GetCategoryStrategy(); err != nil || strategy.Prefix != "/api/v1" {
+		t.Fatalf("strategy = %+v, err = %v", strategy, err)
+	}
+}
@@ -0,0 +1,227 @@
+package internal
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDownloadTimeout = 30 * time.Second
+	DefaultAPIKeyHeader    = "X-API-Key"
+)
+
+// DownloadConfig controls how remote specs are fetched
+type DownloadConfig struct {
+	// Client presents the client certificate and is only used for the trusted origins
+	Client *http.Client
+	// PublicClient fetches from any other origin. It shares the timeout, proxy and CA bundle of
+	// Client but presents no client certificate.
+	PublicClient *http.Client
+	// Headers are only sent to the trusted origins
+	Headers http.Header
+	// Origins are the scheme://host[:port] origins trusted with Headers and the client certificate.
+	// The origin of a remote spec is added for the downloads of that spec.
+	Origins []string
+}
+
+// DefaultDownloadConfig downloads without extra headers, honoring the standard proxy variables
+func DefaultDownloadConfig() DownloadConfig {
+	client := &http.Client{Timeout: DefaultDownloadTimeout}
+	return DownloadConfig{
+		Client:       client,
+		PublicClient: client,
+		Headers:      http.Header{},
+	}
+}
+
+// trusts reports whether requests to u may carry the credentials. External refs can point
+// anywhere the spec author chose, so only the configured origins are trusted.
+func (config DownloadConfig) trusts(u *url.URL) bool {
+	origin := urlOrigin(u)
+	for _, trusted := range config.Origins {
+		if trusted == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// urlOrigin returns the scheme://host[:port] origin of a URL, leaving out the default port
+func urlOrigin(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port := u.Port(); port != "" && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	return scheme + "://" + host
+}
+
+// GetDownloadConfig reads request headers, credentials, TLS settings and the timeout for spec
+// downloads from the environment. Every credential can be given directly or read from a file
+// through the same variable name with a _FILE suffix.
+func GetDownloadConfig() (DownloadConfig, error) {
+	config := DefaultDownloadConfig()
+
+	if origins := os.Getenv("OPENAPI_SPEC_CREDENTIAL_ORIGINS"); origins != "" {
+		for _, origin := range strings.Split(origins, ",") {
+			if origin = strings.TrimSpace(origin); origin == "" {
+				continue
+			}
+			u, err := url.Parse(origin)
+			if err != nil || !isURL(origin) || u.Host == "" {
+				return config, fmt.Errorf("invalid OPENAPI_SPEC_CREDENTIAL_ORIGINS entry %q, expected an http or https origin", origin)
+			}
+			config.Origins = append(config.Origins, urlOrigin(u))
+		}
+	}
+
+	if headers := os.Getenv("OPENAPI_SPEC_HEADERS"); headers != "" {
+		for _, header := range strings.Split(headers, ";") {
+			if strings.TrimSpace(header) == "" {
+				continue
+			}
+			name, value, found := strings.Cut(header, ":")
+			if !found {
+				return config, fmt.Errorf("invalid OPENAPI_SPEC_HEADERS entry %q, expected Name: value", header)
+			}
+			config.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+	}
+
+	token, err := envOrFile("OPENAPI_SPEC_BEARER_TOKEN")
+	if err != nil {
+		return config, err
+	}
+	basicAuth, err := envOrFile("OPENAPI_SPEC_BASIC_AUTH")
+	if err != nil {
+		return config, err
+	}
+	apiKey, err := envOrFile("OPENAPI_SPEC_API_KEY")
+	if err != nil {
+		return config, err
+	}
+	apiKeyHeader := os.Getenv("OPENAPI_SPEC_API_KEY_HEADER")
+	if apiKeyHeader == "" {
+		apiKeyHeader = DefaultAPIKeyHeader
+	}
+
+	// Only one setting may provide the Authorization header, rather than one silently replacing another
+	authorization := []string{}
+	if config.Headers.Get("Authorization") != "" {
+		authorization = append(authorization, "OPENAPI_SPEC_HEADERS")
+	}
+	if token != "" {
+		authorization = append(authorization, "OPENAPI_SPEC_BEARER_TOKEN")
+	}
+	if basicAuth != "" {
+		authorization = append(authorization, "OPENAPI_SPEC_BASIC_AUTH")
+	}
+	if apiKey != "" && strings.EqualFold(apiKeyHeader, "Authorization") {
+		authorization = append(authorization, "OPENAPI_SPEC_API_KEY")
+	}
+	if len(authorization) > 1 {
+		return config, fmt.Errorf("%s all set the Authorization header, configure only one of them", strings.Join(authorization, ", "))
+	}
+
+	if token != "" {
+		config.Headers.Set("Authorization", "Bearer "+token)
+	}
+	if basicAuth != "" {
+		if !strings.Contains(basicAuth, ":") {
+			return config, fmt.Errorf("OPENAPI_SPEC_BASIC_AUTH must be in the form user:password")
+		}
+		config.Headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(basicAuth)))
+	}
+	if apiKey != "" {
+		config.Headers.Set(apiKeyHeader, apiKey)
+	}
+
+	if timeout := os.Getenv("OPENAPI_SPEC_TIMEOUT"); timeout != "" {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil || duration <= 0 {
+			return config, fmt.Errorf("invalid OPENAPI_SPEC_TIMEOUT: %s", timeout)
+		}
+		config.Client.Timeout = duration
+	}
+
+	tlsConfig, err := loadTLSConfig(os.Getenv("OPENAPI_SPEC_CA_CERT"), os.Getenv("OPENAPI_SPEC_CLIENT_CERT"), os.Getenv("OPENAPI_SPEC_CLIENT_KEY"))
+	if err != nil {
+		return config, err
+	}
+	config.PublicClient = &http.Client{Timeout: config.Client.Timeout}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		config.Client.Transport = transport
+
+		// Other origins only get the CA bundle, never the client certificate
+		publicTransport := http.DefaultTransport.(*http.Transport).Clone()
+		publicTransport.TLSClientConfig = &tls.Config{MinVersion: tlsConfig.MinVersion, RootCAs: tlsConfig.RootCAs}
+		config.PublicClient.Transport = publicTransport
+	}
+
+	return config, nil
+}
+
+// loadTLSConfig builds a TLS configuration trusting an extra CA bundle and presenting a client
+// certificate for mTLS. It returns nil when neither is configured.
+func loadTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("OPENAPI_SPEC_CLIENT_CERT and OPENAPI_SPEC_CLIENT_KEY must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// envOrFile reads a value from the named variable, or from the file named by the variable with a _FILE suffix
+func envOrFile(name string) (string, error) {
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s_FILE: %w", name, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,419 @@
+package internal
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// downloadVariables are cleared for every test so the environment of the test run does not leak in
+var downloadVariables = []string{
+	"OPENAPI_SPEC_HEADERS",
+	"OPENAPI_SPEC_BEARER_TOKEN", "OPENAPI_SPEC_BEARER_TOKEN_FILE",
+	"OPENAPI_SPEC_BASIC_AUTH", "OPENAPI_SPEC_BASIC_AUTH_FILE",
+	"OPENAPI_SPEC_API_KEY", "OPENAPI_SPEC_API_KEY_FILE", "OPENAPI_SPEC_API_KEY_HEADER",
+	"OPENAPI_SPEC_CA_CERT", "OPENAPI_SPEC_CLIENT_CERT", "OPENAPI_SPEC_CLIENT_KEY",
+	"OPENAPI_SPEC_TIMEOUT",
+	"OPENAPI_SPEC_CREDENTIAL_ORIGINS",
+}
+
+func setDownloadEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for _, name := range downloadVariables {
+		t.Setenv(name, env[name])
+	}
+}
+
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// downloadWith fetches a URL through a cache using the download configuration of the environment
+func downloadWith(t *testing.T, url string) error {
+	t.Helper()
+	config, err := GetDownloadConfig()
+	if err != nil {
+		t.Fatalf("GetDownloadConfig: %v", err)
+	}
+	// The downloaded URL is the spec itself, whose origin NewSpecRegistry trusts
+	u, err := neturl.Parse(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Origins = append(config.Origins, urlOrigin(u))
+	cache := NewCache(t.TempDir(), time.Hour)
+	cache.SetDownloadConfig(config)
+	_, err = cache.LoadFromURL(url)
+	return err
+}
+
+func TestDownloadCredentials(t *testing.T) {
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	tests := []struct {
+		name string
+		env  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "headers",
+			env:  map[string]string{"OPENAPI_SPEC_HEADERS": "X-Tenant: acme; X-Trace: 1"},
+			want: map[string]string{"X-Tenant": "acme", "X-Trace": "1"},
+		},
+		{
+			name: "bearer",
+			env:  map[string]string{"OPENAPI_SPEC_BEARER_TOKEN": "token"},
+			want: map[string]string{"Authorization": "Bearer token"},
+		},
+		{
+			name: "bearer from file",
+			env:  map[string]string{"OPENAPI_SPEC_BEARER_TOKEN_FILE": writeTempFile(t, "token", "file-token\n")},
+			want: map[string]string{"Authorization": "Bearer file-token"},
+		},
+		{
+			name: "basic",
+			env:  map[string]string{"OPENAPI_SPEC_BASIC_AUTH": "user:secret"},
+			want: map[string]string{"Authorization": basic},
+		},
+		{
+			name: "basic from file",
+			env:  map[string]string{"OPENAPI_SPEC_BASIC_AUTH_FILE": writeTempFile(t, "basic", "user:secret")},
+			want: map[string]string{"Authorization": basic},
+		},
+		{
+			name: "api key",
+			env:  map[string]string{"OPENAPI_SPEC_API_KEY": "key"},
+			want: map[string]string{"X-API-Key": "key"},
+		},
+		{
+			name: "api key from file with custom header",
+			env: map[string]string{
+				"OPENAPI_SPEC_API_KEY_FILE":   writeTempFile(t, "key", "file-key"),
+				"OPENAPI_SPEC_API_KEY_HEADER": "X-Custom-Key",
+			},
+			want: map[string]string{"X-Custom-Key": "file-key", "X-API-Key": ""},
+		},
+		{
+			name: "variable wins over file",
+			env: map[string]string{
+				"OPENAPI_SPEC_BEARER_TOKEN":      "direct",
+				"OPENAPI_SPEC_BEARER_TOKEN_FILE": writeTempFile(t, "ignored", "from-file"),
+			},
+			want: map[string]string{"Authorization": "Bearer direct"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setDownloadEnv(t, tt.env)
+			var received http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r.Header.Clone()
+				w.Write([]byte("openapi: 3.0.3"))
+			}))
+			defer server.Close()
+
+			if err := downloadWith(t, server.URL); err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.want {
+				if got := received.Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+// headerRecorder serves a document and records the headers of every request to it
+type headerRecorder struct {
+	*httptest.Server
+	mu       sync.Mutex
+	received []http.Header
+}
+
+func newHeaderRecorder(t *testing.T, document func() string) *headerRecorder {
+	t.Helper()
+	recorder := &headerRecorder{}
+	recorder.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder.mu.Lock()
+		recorder.received = append(recorder.received, r.Header.Clone())
+		recorder.mu.Unlock()
+		w.Write([]byte(document()))
+	}))
+	t.Cleanup(recorder.Close)
+	return recorder
+}
+
+// header returns the value of a header in every request the server received
+func (recorder *headerRecorder) header(name string) []string {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	values := []string{}
+	for _, header := range recorder.received {
+		values = append(values, header.Get(name))
+	}
+	return values
+}
+
+func TestDownloadCredentialsStayOnTrustedOrigins(t *testing.T) {
+	common := func() string { return "Error:\n  type: object\n" }
+	foreign := newHeaderRecorder(t, common)
+	allowlisted := newHeaderRecorder(t, common)
+	root := newHeaderRecorder(t, func() string {
+		return `openapi: 3.0.3
+info: {title: Refs, version: "1"}
+paths: {}
+components:
+  schemas:
+    Foreign:
+      $ref: "` + foreign.URL + `/common.yaml#/Error"
+    Allowlisted:
+      $ref: "` + allowlisted.URL + `/common.yaml#/Error"
+`
+	})
+
+	setDownloadEnv(t, map[string]string{
+		"OPENAPI_SPEC_BEARER_TOKEN":       "SECRET",
+		"OPENAPI_SPEC_API_KEY":            "KEY",
+		"OPENAPI_SPEC_CREDENTIAL_ORIGINS": allowlisted.URL,
+	})
+	config, err := GetDownloadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewSpecRegistry([]SpecSource{{Name: "refs", URL: root.URL, Headers: map[string]string{"X-Tenant": "acme"}}}, t.TempDir(), config)
+	if err := registry.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		server *headerRecorder
+		want   map[string]string
+	}{
+		{"spec origin", root, map[string]string{"Authorization": "Bearer SECRET", "X-API-Key": "KEY", "X-Tenant": "acme"}},
+		{"allowlisted origin", allowlisted, map[string]string{"Authorization": "Bearer SECRET", "X-API-Key": "KEY", "X-Tenant": "acme"}},
+		{"foreign origin", foreign, map[string]string{"Authorization": "", "X-API-Key": "", "X-Tenant": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, want := range tt.want {
+				got := tt.server.header(name)
+				if len(got) == 0 {
+					t.Fatalf("%s was never requested", tt.server.URL)
+				}
+				for _, value := range got {
+					if value != want {
+						t.Errorf("%s = %q, want %q", name, value, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadRedirectLeavingTrustedOrigins(t *testing.T) {
+	foreign := newHeaderRecorder(t, func() string { return "openapi: 3.0.3" })
+	root := httptest.NewServer(http.RedirectHandler(foreign.URL+"/openapi.yaml", http.StatusFound))
+	defer root.Close()
+
+	setDownloadEnv(t, map[string]string{"OPENAPI_SPEC_API_KEY": "KEY"})
+	err := downloadWith(t, root.URL)
+	if err == nil || !strings.Contains(err.Error(), "trusted with the download credentials") {
+		t.Fatalf("error = %v, want the redirect to be refused", err)
+	}
+	if len(foreign.header("X-API-Key")) != 0 {
+		t.Error("the foreign origin was requested")
+	}
+}
+
+func TestDownloadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		message string
+	}{
+		{
+			name:    "bearer and basic",
+			env:     map[string]string{"OPENAPI_SPEC_BEARER_TOKEN": "token", "OPENAPI_SPEC_BASIC_AUTH": "user:secret"},
+			message: "OPENAPI_SPEC_BEARER_TOKEN, OPENAPI_SPEC_BASIC_AUTH",
+		},
+		{
+			name:    "authorization header and bearer file",
+			env:     map[string]string{"OPENAPI_SPEC_HEADERS": "Authorization: Token x", "OPENAPI_SPEC_BEARER_TOKEN_FILE": writeTempFile(t, "token", "token")},
+			message: "OPENAPI_SPEC_HEADERS, OPENAPI_SPEC_BEARER_TOKEN",
+		},
+		{
+			name:    "api key in the authorization header and basic",
+			env:     map[string]string{"OPENAPI_SPEC_API_KEY": "key", "OPENAPI_SPEC_API_KEY_HEADER": "authorization", "OPENAPI_SPEC_BASIC_AUTH": "user:secret"},
+			message: "OPENAPI_SPEC_BASIC_AUTH, OPENAPI_SPEC_API_KEY",
+		},
+		{
+			name:    "basic without password",
+			env:     map[string]string{"OPENAPI_SPEC_BASIC_AUTH": "user"},
+			message: "user:password",
+		},
+		{
+			name:    "missing credential file",
+			env:     map[string]string{"OPENAPI_SPEC_API_KEY_FILE": filepath.Join(t.TempDir(), "missing")},
+			message: "OPENAPI_SPEC_API_KEY_FILE",
+		},
+		{
+			name:    "credential origin without scheme",
+			env:     map[string]string{"OPENAPI_SPEC_CREDENTIAL_ORIGINS": "schemas.example.com"},
+			message: "OPENAPI_SPEC_CREDENTIAL_ORIGINS",
+		},
+		{
+			name:    "client certificate without key",
+			env:     map[string]string{"OPENAPI_SPEC_CLIENT_CERT": "cert.pem"},
+			message: "must be set together",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setDownloadEnv(t, tt.env)
+			_, err := GetDownloadConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Fatalf("error = %v, want it to mention %q", err, tt.message)
+			}
+		})
+	}
+}
+
+// testCertificate is a certificate with its key, signed by parent or self-signed when parent is nil
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestDownloadMutualTLS(t *testing.T) {
+	ca := newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	serverCert := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	clientCert := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "client"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	serverPair, err := tls.X509KeyPair(serverCert.certPEM, serverCert.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("openapi: 3.0.3"))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverPair},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := writeTempFile(t, "ca.pem", string(ca.certPEM))
+	certFile := writeTempFile(t, "client.pem", string(clientCert.certPEM))
+	keyFile := writeTempFile(t, "client-key.pem", string(clientCert.keyPEM))
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		success bool
+	}{
+		{
+			name:    "CA and client certificate",
+			env:     map[string]string{"OPENAPI_SPEC_CA_CERT": caFile, "OPENAPI_SPEC_CLIENT_CERT": certFile, "OPENAPI_SPEC_CLIENT_KEY": keyFile},
+			success: true,
+		},
+		{
+			name: "CA without client certificate",
+			env:  map[string]string{"OPENAPI_SPEC_CA_CERT": caFile},
+		},
+		{
+			name: "client certificate without CA",
+			env:  map[string]string{"OPENAPI_SPEC_CLIENT_CERT": certFile, "OPENAPI_SPEC_CLIENT_KEY": keyFile},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setDownloadEnv(t, tt.env)
+			err := downloadWith(t, server.URL)
+			if tt.success && err != nil {
+				t.Fatalf("download failed: %v", err)
+			}
+			if !tt.success && err == nil {
+				t.Fatal("download succeeded without the required certificates")
+			}
+		})
+	}
+}
@@ -0,0 +1,604 @@
+package internal
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// MaxExampleDepth bounds how deep generated examples nest, on top of cycle detection
+	MaxExampleDepth = 8
+
+	exampleTargetRequest  = "request"
+	exampleTargetResponse = "response"
+)
+
+// exampleBaseTime anchors generated dates so the output only depends on the seed
+var exampleBaseTime = time.Date(2024, time.January, 15, 9, 30, 0, 0, time.UTC)
+
+// exampleGenerator synthesizes values from schemas. Values documented in the spec win over
+// synthesized ones; random choices come from a seeded source so the same seed gives the same output.
+type exampleGenerator struct {
+	rng *rand.Rand
+	// request leaves out readOnly properties, responses leave out writeOnly ones
+	request bool
+	// active tracks the schemas being generated to stop at recursive references
+	active map[*openapi3.Schema]bool
+	// schemas resolves component references the loader leaves alone, such as in 3.1 tuples
+	schemas openapi3.Schemas
+	// warnings name the generated values that break their schema, such as patterns too complex
+	// to generate from
+	warnings []string
+}
+
+func (oas *OpenAPIServer) newExampleGenerator(seed int64, request bool) *exampleGenerator {
+	generator := &exampleGenerator{
+		rng:     rand.New(rand.NewSource(seed)),
+		request: request,
+		active:  map[*openapi3.Schema]bool{},
+	}
+	if oas.spec.Components != nil {
+		generator.schemas = oas.spec.Components.Schemas
+	}
+	return generator
+}
+
+// generate returns an example value for the schema. ok is false when no value could be produced
+// because of recursion, in which case optional properties are left out.
+func (g *exampleGenerator) generate(schemaRef *openapi3.SchemaRef, depth int) (value interface{}, ok bool) {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return nil, true
+	}
+	schema := schemaRef.Value
+	if g.active[schema] || depth > MaxExampleDepth {
+		return nil, false
+	}
+	g.active[schema] = true
+	defer delete(g.active, schema)
+
+	if len(schema.AllOf) > 0 {
+		merged := mergeAllOf(schemaRef).Value
+		g.active[merged] = true
+		defer delete(g.active, merged)
+		schema = merged
+	}
+
+	// Documented values first. They are copied, the caller may fill in a discriminator and the
+	// loaded spec is shared by every tool call.
+	if schema.Example != nil {
+		return copyValue(schema.Example), true
+	}
+	if examples, isList := schema.Extensions["examples"].([]interface{}); isList && len(examples) > 0 {
+		return copyValue(examples[0]), true
+	}
+	if schema.Default != nil {
+		return copyValue(schema.Default), true
+	}
+	if constValue, exists := schema.Extensions["const"]; exists {
+		return copyValue(constValue), true
+	}
+	if len(schema.Enum) > 0 {
+		return copyValue(schema.Enum[g.rng.Intn(len(schema.Enum))]), true
+	}
+
+	if members := append(append(openapi3.SchemaRefs{}, schema.OneOf...), schema.AnyOf...); len(members) > 0 {
+		return g.generateVariant(schema, members, depth)
+	}
+
+	switch {
+	case schema.Type.Is(openapi3.TypeObject), schema.Type == nil && (len(schema.Properties) > 0 || schema.AdditionalProperties.Schema != nil):
+		return g.generateObject(schema, depth), true
+	case schema.Type.Is(openapi3.TypeArray), schema.Type == nil && schema.Items != nil:
+		return g.generateArray(schema, depth)
+	case schema.Type.Is(openapi3.TypeString):
+		return g.generateString(schema), true
+	case schema.Type.Is(openapi3.TypeInteger):
+		return int64(g.generateNumber(schema, true)), true
+	case schema.Type.Is(openapi3.TypeNumber):
+		return g.generateNumber(schema, false), true
+	case schema.Type.Is(openapi3.TypeBoolean):
+		return g.rng.Intn(2) == 1, true
+	}
+
+	// Schemas without a type accept anything
+	return nil, true
+}
+
+// generateVariant picks one oneOf/anyOf member and sets the discriminator property to match it
+func (g *exampleGenerator) generateVariant(schema *openapi3.Schema, members openapi3.SchemaRefs, depth int) (interface{}, bool) {
+	// Try the members starting from a seeded position, skipping recursive ones
+	start := g.rng.Intn(len(members))
+	for i := range members {
+		member := members[(start+i)%len(members)]
+		value, ok := g.generate(member, depth+1)
+		if !ok {
+			continue
+		}
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return value, true
+		}
+		// Properties declared next to oneOf/anyOf apply to every variant
+		if len(schema.Properties) > 0 {
+			for name, propertyValue := range g.generateObject(schema, depth) {
+				if _, exists := object[name]; !exists {
+					object[name] = propertyValue
+				}
+			}
+		}
+		if schema.Discriminator != nil && member.Ref != "" {
+			object[schema.Discriminator.PropertyName] = discriminatorValue(schema.Discriminator, member.Ref)
+		}
+		return object, true
+	}
+	return nil, false
+}
+
+// copyValue deep copies the maps and slices of a decoded JSON value
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	}
+	return value
+}
+
+// discriminatorValue returns the value selecting ref, from the mapping or else the schema name
+func discriminatorValue(discriminator *openapi3.Discriminator, ref string) string {
+	values := make([]string, 0, len(discriminator.Mapping))
+	for value := range discriminator.Mapping {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		mapped := discriminator.Mapping[value]
+		if mapped == ref || refName(mapped) == refName(ref) {
+			return value
+		}
+	}
+	return refName(ref)
+}
+
+func (g *exampleGenerator) generateObject(schema *openapi3.Schema, depth int) map[string]interface{} {
+	object := map[string]interface{}{}
+
+	// Iterate in a stable order so the random choices line up between calls
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property := schema.Properties[name]
+		if property == nil || property.Value == nil {
+			continue
+		}
+		if (g.request && property.Value.ReadOnly) || (!g.request && property.Value.WriteOnly) {
+			continue
+		}
+		value, ok := g.generate(property, depth+1)
+		if !ok {
+			// A recursive optional property is left out; a required one ends the recursion with null
+			if !slices.Contains(schema.Required, name) {
+				continue
+			}
+			value = nil
+		}
+		object[name] = value
+	}
+
+	if schema.AdditionalProperties.Schema != nil && len(schema.Properties) == 0 {
+		if value, ok := g.generate(schema.AdditionalProperties.Schema, depth+1); ok {
+			object["key"] = value
+		}
+	}
+
+	return object
+}
+
+func (g *exampleGenerator) generateArray(schema *openapi3.Schema, depth int) (interface{}, bool) {
+	count := max(int(schema.MinItems), 1)
+	if schema.MaxItems != nil && uint64(count) > *schema.MaxItems {
+		count = int(*schema.MaxItems)
+	}
+
+	items := []interface{}{}
+
+	// OpenAPI 3.1 tuples start with their positional item schemas
+	if prefixItems, isList := schema.Extensions["prefixItems"].([]interface{}); isList {
+		for _, raw := range prefixItems {
+			prefixItem := rawSchemaRef(raw)
+			if prefixItem != nil && prefixItem.Value == nil {
+				// Tuple members are not resolved by the loader
+				prefixItem = g.schemas[strings.TrimPrefix(prefixItem.Ref, "#/components/schemas/")]
+			}
+			value, _ := g.generate(prefixItem, depth+1)
+			items = append(items, value)
+		}
+		return items, true
+	}
+
+	for len(items) < count {
+		value, ok := g.generate(schema.Items, depth+1)
+		if !ok {
+			if schema.MinItems > 0 {
+				return nil, false
+			}
+			break
+		}
+		items = append(items, value)
+	}
+	return items, true
+}
+
+func (g *exampleGenerator) generateString(schema *openapi3.Schema) string {
+	value := g.formattedString(schema)
+	if schema.Pattern == "" {
+		return value
+	}
+
+	// Patterns win over formats, values that match neither are flagged rather than passed off as valid
+	re, err := regexp.Compile(schema.Pattern)
+	if err == nil && re.MatchString(value) {
+		return value
+	}
+	if err == nil {
+		if generated, ok := g.patternString(schema, re); ok {
+			return generated
+		}
+	}
+	warning := fmt.Sprintf("the generated value %q does not match the pattern %s", value, schema.Pattern)
+	if !slices.Contains(g.warnings, warning) {
+		g.warnings = append(g.warnings, warning)
+	}
+	return value
+}
+
+// patternString generates a string matching the pattern within the length constraints. Only a
+// few attempts are made, so a pattern that is hard to satisfy may still fail.
+func (g *exampleGenerator) patternString(schema *openapi3.Schema, re *regexp.Regexp) (string, bool) {
+	parsed, err := syntax.Parse(schema.Pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	parsed = parsed.Simplify()
+
+	for attempt := 0; attempt < 20; attempt++ {
+		var builder strings.Builder
+		if !g.writePattern(&builder, parsed) {
+			return "", false
+		}
+		value := builder.String()
+		length := uint64(utf8.RuneCountInString(value))
+		if re.MatchString(value) && length >= schema.MinLength && (schema.MaxLength == nil || length <= *schema.MaxLength) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// writePattern writes a random string matched by the regular expression. Unbounded repetitions
+// are kept short.
+func (g *exampleGenerator) writePattern(builder *strings.Builder, re *syntax.Regexp) bool {
+	repeat := func(low, high int) bool {
+		if high < 0 {
+			high = low + 3
+		}
+		for i := low + g.rng.Intn(high-low+1); i > 0; i-- {
+			if !g.writePattern(builder, re.Sub[0]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch re.Op {
+	case syntax.OpLiteral:
+		builder.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		r, ok := g.classRune(re.Rune)
+		if !ok {
+			return false
+		}
+		builder.WriteRune(r)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		builder.WriteRune(rune('a' + g.rng.Intn(26)))
+	case syntax.OpCapture:
+		return g.writePattern(builder, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !g.writePattern(builder, sub) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		return g.writePattern(builder, re.Sub[g.rng.Intn(len(re.Sub))])
+	case syntax.OpStar:
+		return repeat(0, -1)
+	case syntax.OpPlus:
+		return repeat(1, -1)
+	case syntax.OpQuest:
+		return repeat(0, 1)
+	case syntax.OpRepeat:
+		return repeat(re.Min, re.Max)
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		// Zero-width, the final match check catches boundaries that do not hold
+	default:
+		return false
+	}
+	return true
+}
+
+// classRune picks a rune from a character class given as lo-hi pairs, preferring printable ASCII
+func (g *exampleGenerator) classRune(ranges []rune) (rune, bool) {
+	printable := []rune{}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if low, high := max(ranges[i], ' '), min(ranges[i+1], '~'); low <= high {
+			printable = append(printable, low, high)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) < 2 {
+		return 0, false
+	}
+	pair := g.rng.Intn(len(ranges)/2) * 2
+	low, high := ranges[pair], ranges[pair+1]
+	return low + rune(g.rng.Intn(int(high-low)+1)), true
+}
+
+// formattedString returns a value for the string's format, or a plain string padded or cut to the
+// length constraints
+func (g *exampleGenerator) formattedString(schema *openapi3.Schema) string {
+	value, formatted := "", true
+	switch schema.Format {
+	case "date-time":
+		value = g.exampleTime().Format(time.RFC3339)
+	case "date":
+		value = g.exampleTime().Format(time.DateOnly)
+	case "time":
+		value = g.exampleTime().Format(time.TimeOnly)
+	case "uuid":
+		bytes := make([]byte, 16)
+		g.rng.Read(bytes)
+		bytes[6] = bytes[6]&0x0f | 0x40 // version 4
+		bytes[8] = bytes[8]&0x3f | 0x80 // RFC 4122 variant
+		value = fmt.Sprintf("%x-%x-%x-%x-%x", bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:16])
+	case "email":
+		value = fmt.Sprintf("user%d@example.com", g.rng.Intn(1000))
+	case "uri", "url":
+		value = fmt.Sprintf("https://example.com/resource/%d", g.rng.Intn(1000))
+	case "hostname":
+		value = "api.example.com"
+	case "ipv4":
+		value = fmt.Sprintf("192.0.2.%d", g.rng.Intn(255)+1)
+	case "ipv6":
+		value = fmt.Sprintf("2001:db8::%x", g.rng.Intn(0xffff)+1)
+	case "byte":
+		value = base64.StdEncoding.EncodeToString([]byte("example"))
+	case "binary":
+		value = "<binary>"
+	case "password":
+		value = "********"
+	default:
+		value, formatted = "string", false
+	}
+
+	// Pad or cut plain strings to the length constraints; formatted values keep their shape
+	// so they stay valid for their format
+	if formatted {
+		return value
+	}
+	if schema.MinLength > 0 && uint64(len(value)) < schema.MinLength {
+		value += strings.Repeat("x", int(schema.MinLength)-len(value))
+	}
+	if schema.MaxLength != nil && uint64(len(value)) > *schema.MaxLength {
+		value = value[:*schema.MaxLength]
+	}
+	return value
+}
+
+// exampleTime returns a date within a year after exampleBaseTime
+func (g *exampleGenerator) exampleTime() time.Time {
+	return exampleBaseTime.Add(time.Duration(g.rng.Intn(365*24)) * time.Hour)
+}
+
+// generateNumber picks a value within the bounds, honoring exclusive bounds and multipleOf
+func (g *exampleGenerator) generateNumber(schema *openapi3.Schema, integer bool) float64 {
+	step := 0.01
+	if integer {
+		step = 1
+	}
+
+	low, high := 1.0, 100.0
+	if schema.Min != nil {
+		low = *schema.Min
+		if schema.ExclusiveMin {
+			low += step
+		}
+		if schema.Max == nil {
+			high = low + 99
+		}
+	}
+	if schema.Max != nil {
+		high = *schema.Max
+		if schema.ExclusiveMax {
+			high -= step
+		}
+		if schema.Min == nil {
+			low = min(low, high-99)
+		}
+	}
+	if integer {
+		low, high = math.Ceil(low), math.Floor(high)
+	}
+	if high < low {
+		return low
+	}
+
+	value := low + g.rng.Float64()*(high-low)
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		multiple := *schema.MultipleOf
+		value = math.Ceil(value/multiple) * multiple
+		if value > high {
+			value -= multiple
+		}
+	}
+	if integer {
+		return math.Round(value)
+	}
+	return math.Round(value*100) / 100
+}
+
+// selectResponse returns the response for a status code, defaulting to the first documented
+// success response and then the default response
+func selectResponse(operation *openapi3.Operation, status string) (string, *openapi3.Response, error) {
+	if operation.Responses == nil || operation.Responses.Len() == 0 {
+		return "", nil, fmt.Errorf("No responses documented")
+	}
+
+	if status == "" {
+		statuses := make([]string, 0, operation.Responses.Len())
+		for code := range operation.Responses.Map() {
+			statuses = append(statuses, code)
+		}
+		sort.Strings(statuses)
+		for _, code := range statuses {
+			if strings.HasPrefix(code, "2") {
+				status = code
+				break
+			}
+		}
+		if status == "" {
+			status = "default"
+		}
+	}
+
+	responseRef := operation.Responses.Value(status)
+	if responseRef == nil || responseRef.Value == nil {
+		return status, nil, fmt.Errorf("Response not found for status: %s", status)
+	}
+	return status, responseRef.Value, nil
+}
+
+// selectMediaType returns the requested media type, defaulting to JSON and then to the first one
+func selectMediaType(content openapi3.Content, mediaType string) (string, *openapi3.MediaType, error) {
+	if len(content) == 0 {
+		return "", nil, fmt.Errorf("No content documented")
+	}
+
+	if mediaType != "" {
+		if media := content.Get(mediaType); media != nil {
+			return mediaType, media, nil
+		}
+		return "", nil, fmt.Errorf("Media type not found: %s. Available: %s", mediaType, strings.Join(sortedKeys(content), ", "))
+	}
+
+	names := sortedKeys(content)
+	for _, name := range names {
+		if name == "application/json" || strings.HasSuffix(name, "+json") {
+			return name, content[name], nil
+		}
+	}
+	return names[0], content[names[0]], nil
+}
+
+// mediaTypeExample returns an example for a media type: the documented example, then the first
+// named example, then one synthesized from the schema
+func mediaTypeExample(media *openapi3.MediaType, generator *exampleGenerator) (interface{}, string) {
+	if media.Example != nil {
+		return media.Example, "example"
+	}
+	for _, name := range sortedKeys(media.Examples) {
+		if example := media.Examples[name]; example != nil && example.Value != nil && example.Value.Value != nil {
+			return example.Value.Value, "examples/" + name
+		}
+	}
+	value, _ := generator.generate(media.Schema, 0)
+	return value, "schema"
+}
+
+func (oas *OpenAPIServer) generateExampleHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	method, err := request.RequireString("method")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	_, operation, err := oas.findOperation(path, method)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	target := request.GetString("target", exampleTargetRequest)
+	result := map[string]interface{}{
+		"path":   path,
+		"method": strings.ToUpper(method),
+		"target": target,
+	}
+
+	var content openapi3.Content
+	switch target {
+	case exampleTargetRequest:
+		if operation.RequestBody == nil || operation.RequestBody.Value == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s %s has no request body", strings.ToUpper(method), path)), nil
+		}
+		content = operation.RequestBody.Value.Content
+	case exampleTargetResponse:
+		status, response, err := selectResponse(operation, request.GetString("status", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		result["status"] = status
+		content = response.Content
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid target: %s. Expected request or response", target)), nil
+	}
+
+	mediaType, media, err := selectMediaType(content, request.GetString("media_type", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	generator := oas.newExampleGenerator(int64(request.GetInt("seed", 0)), target == exampleTargetRequest)
+	example, source := mediaTypeExample(media, generator)
+
+	result["mediaType"] = mediaType
+	result["source"] = source
+	result["example"] = example
+	if len(generator.warnings) > 0 {
+		result["warnings"] = generator.warnings
+	}
+
+	return JSONResponse(result)
+}
@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const exampleSpec = `
+openapi: 3.0.3
+info: {title: examples, version: "1"}
+paths:
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [id, kind, tags]
+              properties:
+                id: {type: string, format: uuid}
+                born: {type: string, format: date-time}
+                weight: {type: number, minimum: 1, maximum: 50}
+                kind: {type: string, enum: [cat, dog, bird, fish]}
+                tags:
+                  type: array
+                  minItems: 2
+                  items: {type: integer, minimum: 1, maximum: 1000}
+                owner:
+                  oneOf:
+                    - {type: object, properties: {email: {type: string, format: email}}}
+                    - {type: object, properties: {phone: {type: string}}}
+      responses:
+        "201": {description: created}
+`
+
+func TestGenerateExampleSeed(t *testing.T) {
+	oas := loadTestSpec(t, exampleSpec)
+	generate := func(seed int) string {
+		text, isError := callTool(t, oas.generateExampleHandler, map[string]interface{}{
+			"path": "/pets", "method": "post", "seed": seed,
+		})
+		if isError {
+			t.Fatalf("generate_example failed: %s", text)
+		}
+		return text
+	}
+
+	first := generate(42)
+	for i := 0; i < 5; i++ {
+		if again := generate(42); again != first {
+			t.Fatalf("seed 42 gave different examples:\n%s\n%s", first, again)
+		}
+	}
+	if other := generate(7); other == first {
+		t.Fatalf("seeds 42 and 7 gave the same example:\n%s", first)
+	}
+}
+
+func TestGenerateStringLength(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	valid := map[string]func(string) bool{
+		"uuid":      uuid.MatchString,
+		"email":     func(value string) bool { _, err := mail.ParseAddress(value); return err == nil },
+		"date":      func(value string) bool { _, err := time.Parse(time.DateOnly, value); return err == nil },
+		"date-time": func(value string) bool { _, err := time.Parse(time.RFC3339, value); return err == nil },
+	}
+
+	oas := loadTestSpec(t, exampleSpec)
+	for format, isValid := range valid {
+		for constraint, schema := range map[string]*openapi3.Schema{
+			"minLength 100": {Type: &openapi3.Types{openapi3.TypeString}, Format: format, MinLength: 100},
+			"maxLength 3":   {Type: &openapi3.Types{openapi3.TypeString}, Format: format, MaxLength: openapi3.Uint64Ptr(3)},
+		} {
+			if value := oas.newExampleGenerator(1, false).generateString(schema); !isValid(value) {
+				t.Errorf("format %s with %s gave invalid value %q", format, constraint, value)
+			}
+		}
+	}
+
+	plain := &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeString}, MinLength: 10}
+	if value := oas.newExampleGenerator(1, false).generateString(plain); value != "string"+strings.Repeat("x", 4) {
+		t.Errorf("plain string with minLength 10 gave %q", value)
+	}
+	plain = &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeString}, MaxLength: openapi3.Uint64Ptr(3)}
+	if value := oas.newExampleGenerator(1, false).generateString(plain); value != "str" {
+		t.Errorf("plain string with maxLength 3 gave %q", value)
+	}
+}
+
+func TestGenerateStringPattern(t *testing.T) {
+	oas := loadTestSpec(t, exampleSpec)
+	for _, pattern := range []string{
+		`^[A-Z]{3}-\d{4}$`,
+		`^(cat|dog)s?$`,
+		`^[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}$`,
+		`^\+?[1-9]\d{7,14}$`,
+		`v\d+`,
+	} {
+		schema := &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeString}, Pattern: pattern, MaxLength: openapi3.Uint64Ptr(40)}
+		for seed := int64(0); seed < 10; seed++ {
+			generator := oas.newExampleGenerator(seed, false)
+			value := generator.generateString(schema)
+			if !regexp.MustCompile(pattern).MatchString(value) || len(generator.warnings) > 0 {
+				t.Errorf("pattern %s, seed %d: got %q, warnings %v", pattern, seed, value, generator.warnings)
+			}
+			if err := schema.VisitJSON(value); err != nil {
+				t.Errorf("pattern %s, seed %d: %q fails validation: %v", pattern, seed, value, err)
+			}
+		}
+	}
+
+	// A value that cannot satisfy the pattern is flagged
+	impossible := &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeString}, Pattern: `^\d{10}$`, MaxLength: openapi3.Uint64Ptr(5)}
+	generator := oas.newExampleGenerator(1, false)
+	generator.generateString(impossible)
+	if len(generator.warnings) != 1 || !strings.Contains(generator.warnings[0], `^\d{10}$`) {
+		t.Errorf("warnings = %v, want one naming the pattern", generator.warnings)
+	}
+}
+
+// variantSpec answers with a discriminated oneOf whose members document object examples
+const variantSpec = `
+openapi: 3.0.3
+info: {title: variants, version: "1"}
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  owner: {type: string, example: alice}
+                oneOf:
+                  - $ref: "#/components/schemas/Cat"
+                  - $ref: "#/components/schemas/Dog"
+                discriminator:
+                  propertyName: petType
+                  mapping:
+                    kitty: "#/components/schemas/Cat"
+components:
+  schemas:
+    Cat:
+      type: object
+      properties:
+        petType: {type: string}
+        lives: {type: integer}
+      example: {lives: 9}
+    Dog:
+      type: object
+      properties:
+        petType: {type: string}
+        barks: {type: boolean}
+      example: {barks: true}
+`
+
+func TestGenerateExampleLeavesSpecUnchanged(t *testing.T) {
+	oas := loadTestSpec(t, variantSpec)
+	for seed := 0; seed < 4; seed++ {
+		var result map[string]interface{}
+		callToolJSON(t, oas.generateExampleHandler, map[string]interface{}{
+			"path": "/pets", "method": "get", "target": "response", "seed": seed,
+		}, &result)
+		example := result["example"].(map[string]interface{})
+		if example["petType"] == nil || example["owner"] != "alice" {
+			t.Fatalf("variant example misses the discriminator or shared property: %v", example)
+		}
+	}
+
+	// The discriminator and shared properties are set on a copy, not on the documented examples
+	schemas := oas.spec.Components.Schemas
+	if cat := schemas["Cat"].Value.Example; !reflect.DeepEqual(cat, map[string]interface{}{"lives": float64(9)}) {
+		t.Errorf("Cat example was changed to %v", cat)
+	}
+	if dog := schemas["Dog"].Value.Example; !reflect.DeepEqual(dog, map[string]interface{}{"barks": true}) {
+		t.Errorf("Dog example was changed to %v", dog)
+	}
+}
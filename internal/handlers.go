@@ -10,17 +10,19 @@ import (
 )
 
 func (oas *OpenAPIServer) listCategoriesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Extract unique first path segments as categories
+	// Count endpoint paths per category using the configured grouping strategy. In tag mode a path
+	// counts towards every category one of its operations is in.
 	categoriesMap := make(map[string]int)
 
-	for path := range oas.spec.Paths.Map() {
-		// Remove leading slash and get first segment
-		trimmedPath := strings.TrimPrefix(path, "/")
-		segments := strings.Split(trimmedPath, "/")
-		if len(segments) > 0 && segments[0] != "" {
-			// Extract the base category (remove any path parameters)
-			category := strings.Split(segments[0], "{")[0]
-			categoriesMap[category]++
+	for path, pathItem := range oas.spec.Paths.Map() {
+		counted := map[string]bool{}
+		for _, operation := range pathItem.Operations() {
+			for _, category := range oas.categoryStrategy.Categories(path, operation) {
+				if !counted[category] {
+					counted[category] = true
+					categoriesMap[category]++
+				}
+			}
 		}
 	}
 
@@ -31,10 +33,16 @@ func (oas *OpenAPIServer) listCategoriesHandler(ctx context.Context, request mcp
 	// Convert to sorted slice
 	categories := make([]map[string]interface{}, 0, len(categoriesMap))
 	for name, count := range categoriesMap {
-		categories = append(categories, map[string]interface{}{
+		category := map[string]interface{}{
 			"name":           name,
 			"endpoint_count": count,
-		})
+		}
+		if oas.categoryStrategy.Mode == CategoryModeTag {
+			if description := tagDescription(oas.spec, name); description != "" {
+				category["description"] = description
+			}
+		}
+		categories = append(categories, category)
 	}
 
 	// Sort categories by name
@@ -47,47 +55,34 @@ func (oas *OpenAPIServer) listEndpointsHandler(ctx context.Context, request mcp.
 	// Category is optional
 	categoryFilter := request.GetString("category", "")
 
-	endpoints := []map[string]interface{}{}
-
-	for path, pathItem := range oas.spec.Paths.Map() {
-		// Check if we should include this path
-		includeEndpoint := false
-
-		if categoryFilter == "" {
-			// No filter, include all endpoints
-			includeEndpoint = true
-		} else {
-			// Check if path starts with the category
-			trimmedPath := strings.TrimPrefix(path, "/")
-			segments := strings.Split(trimmedPath, "/")
+	// Pagination is optional; without a limit every endpoint is returned in a single page
+	limit := request.GetInt("limit", 0)
+	offset, err := DecodeCursor(request.GetString("cursor", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-			if len(segments) > 0 && segments[0] != "" {
-				// Extract the base category (remove any path parameters)
-				pathCategory := strings.Split(segments[0], "{")[0]
+	endpoints := []map[string]interface{}{}
 
-				// Check if this path belongs to the requested category
-				if strings.EqualFold(pathCategory, categoryFilter) {
-					includeEndpoint = true
-				}
-			}
+	// Iterate in a stable order so repeated calls and cursors line up
+	for _, entry := range sortedOperations(oas.spec) {
+		// Check if this endpoint belongs to the requested category
+		if categoryFilter != "" && !oas.inCategory(entry.Path, entry.Operation, categoryFilter) {
+			continue
 		}
 
-		if includeEndpoint {
-			for method, operation := range pathItem.Operations() {
-				endpoint := map[string]interface{}{
-					"path":    path,
-					"method":  method,
-					"summary": operation.Summary,
-				}
-				if operation.Description != "" {
-					endpoint["description"] = operation.Description
-				}
-				if operation.OperationID != "" {
-					endpoint["operationId"] = operation.OperationID
-				}
-				endpoints = append(endpoints, endpoint)
-			}
+		endpoint := map[string]interface{}{
+			"path":    entry.Path,
+			"method":  entry.Method,
+			"summary": entry.Operation.Summary,
+		}
+		if entry.Operation.Description != "" {
+			endpoint["description"] = entry.Operation.Description
+		}
+		if entry.Operation.OperationID != "" {
+			endpoint["operationId"] = entry.Operation.OperationID
 		}
+		endpoints = append(endpoints, endpoint)
 	}
 
 	if len(endpoints) == 0 {
@@ -97,7 +92,17 @@ func (oas *OpenAPIServer) listEndpointsHandler(ctx context.Context, request mcp.
 		return mcp.NewToolResultText("No endpoints found in the OpenAPI specification"), nil
 	}
 
-	return JSONResponse(endpoints)
+	// Every result has the same shape, a page holding all endpoints when no limit is given
+	return JSONResponse(Paginate(endpoints, offset, limit))
+}
+
+func (oas *OpenAPIServer) inCategory(path string, operation *openapi3.Operation, category string) bool {
+	for _, candidate := range oas.categoryStrategy.Categories(path, operation) {
+		if strings.EqualFold(candidate, category) {
+			return true
+		}
+	}
+	return false
 }
 
 func (oas *OpenAPIServer) showEndpointHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -111,88 +116,133 @@ func (oas *OpenAPIServer) showEndpointHandler(ctx context.Context, request mcp.C
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	pathItem := oas.spec.Paths.Find(path)
-	if pathItem == nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Path not found: %s", path)), nil
+	pathItem, operation, err := oas.findOperation(path, method)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	operation := pathItem.GetOperation(strings.ToUpper(method))
-	if operation == nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Method %s not found for path: %s", method, path)), nil
-	}
+	result := oas.operationToMap(pathItem, operation, request.GetBool("merged", false))
+	result["path"] = path
+	result["method"] = strings.ToUpper(method)
 
+	return JSONResponse(result)
+}
+
+// operationToMap renders an operation with its parameters, security, request body and responses
+func (oas *OpenAPIServer) operationToMap(pathItem *openapi3.PathItem, operation *openapi3.Operation, merged bool) map[string]interface{} {
 	result := map[string]interface{}{
-		"path":        path,
-		"method":      strings.ToUpper(method),
 		"summary":     operation.Summary,
 		"description": operation.Description,
 		"operationId": operation.OperationID,
 		"tags":        operation.Tags,
 	}
 
-	// Add parameters
-	if operation.Parameters != nil && len(operation.Parameters) > 0 {
+	// Add parameters, including those shared at path level
+	if declared := effectiveParameters(pathItem, operation); len(declared) > 0 {
 		params := []map[string]interface{}{}
-		for _, paramRef := range operation.Parameters {
-			param := paramRef.Value
-			paramInfo := map[string]interface{}{
-				"name":        param.Name,
-				"in":          param.In,
-				"required":    param.Required,
-				"description": param.Description,
-			}
-			if param.Schema != nil && param.Schema.Value != nil {
-				paramInfo["type"] = param.Schema.Value.Type
-				if param.Schema.Value.Format != "" {
-					paramInfo["format"] = param.Schema.Value.Format
-				}
-			}
-			params = append(params, paramInfo)
+		for _, declaredParam := range declared {
+			params = append(params, oas.parameterToMap(declaredParam))
 		}
 		result["parameters"] = params
 	}
 
+	// Add effective security, which either overrides or inherits the global requirements
+	requirements, source := effectiveSecurity(oas.spec, operation)
+	result["security"] = oas.securityToMap(requirements, source)
+
 	// Add request body
 	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
-		reqBody := map[string]interface{}{
-			"description": operation.RequestBody.Value.Description,
-			"required":    operation.RequestBody.Value.Required,
-		}
-		if operation.RequestBody.Value.Content != nil {
-			content := map[string]interface{}{}
-			for mediaType, mediaTypeObj := range operation.RequestBody.Value.Content {
-				if mediaTypeObj.Schema != nil {
-					content[mediaType] = oas.schemaToMap(mediaTypeObj.Schema)
-				}
-			}
-			reqBody["content"] = content
-		}
-		result["requestBody"] = reqBody
+		result["requestBody"] = oas.requestBodyToMap(operation.RequestBody.Value, merged)
 	}
 
 	// Add responses
 	if operation.Responses != nil {
 		responses := map[string]interface{}{}
 		for statusCode, responseRef := range operation.Responses.Map() {
-			response := responseRef.Value
-			respInfo := map[string]interface{}{
-				"description": response.Description,
+			if responseRef.Value != nil {
+				responses[statusCode] = oas.responseToMap(responseRef.Value, merged)
 			}
-			if response.Content != nil {
-				content := map[string]interface{}{}
-				for mediaType, mediaTypeObj := range response.Content {
-					if mediaTypeObj.Schema != nil {
-						content[mediaType] = oas.schemaToMap(mediaTypeObj.Schema)
-					}
-				}
-				respInfo["content"] = content
-			}
-			responses[statusCode] = respInfo
 		}
 		result["responses"] = responses
 	}
 
-	return JSONResponse(result)
+	return result
+}
+
+func (oas *OpenAPIServer) requestBodyToMap(requestBody *openapi3.RequestBody, merged bool) map[string]interface{} {
+	reqBody := map[string]interface{}{
+		"description": requestBody.Description,
+		"required":    requestBody.Required,
+	}
+	if requestBody.Content != nil {
+		reqBody["content"] = oas.contentToMap(requestBody.Content, merged)
+	}
+	return reqBody
+}
+
+func (oas *OpenAPIServer) responseToMap(response *openapi3.Response, merged bool) map[string]interface{} {
+	respInfo := map[string]interface{}{
+		"description": response.Description,
+	}
+	if response.Content != nil {
+		respInfo["content"] = oas.contentToMap(response.Content, merged)
+	}
+	return respInfo
+}
+
+func (oas *OpenAPIServer) contentToMap(content openapi3.Content, merged bool) map[string]interface{} {
+	result := map[string]interface{}{}
+	for mediaType, mediaTypeObj := range content {
+		if mediaTypeObj.Schema != nil {
+			result[mediaType] = oas.contentSchemaToMap(mediaTypeObj.Schema, merged)
+		}
+	}
+	return result
+}
+
+func (oas *OpenAPIServer) parameterToMap(declaredParam declaredParameter) map[string]interface{} {
+	param := declaredParam.Ref.Value
+	paramInfo := map[string]interface{}{
+		"name":        param.Name,
+		"in":          param.In,
+		"required":    param.Required,
+		"description": param.Description,
+	}
+	if declaredParam.DeclaredAt != "" {
+		paramInfo["declaredAt"] = declaredParam.DeclaredAt
+	}
+	if declaredParam.Ref.Ref != "" {
+		paramInfo["$ref"] = declaredParam.Ref.Ref
+	}
+	if param.Deprecated {
+		paramInfo["deprecated"] = true
+	}
+	if param.Style != "" {
+		paramInfo["style"] = param.Style
+	}
+	if param.Explode != nil {
+		paramInfo["explode"] = *param.Explode
+	}
+	if param.Example != nil {
+		paramInfo["example"] = param.Example
+	}
+	if param.Schema != nil && param.Schema.Value != nil {
+		// Expand referenced parameter schemas inline so their constraints are visible
+		paramInfo["schema"] = oas.schemaToMap(&openapi3.SchemaRef{Value: param.Schema.Value})
+		if param.Schema.Ref != "" {
+			paramInfo["schemaRef"] = param.Schema.Ref
+		}
+	}
+	if len(param.Content) > 0 {
+		content := map[string]interface{}{}
+		for mediaType, mediaTypeObj := range param.Content {
+			if mediaTypeObj.Schema != nil {
+				content[mediaType] = oas.schemaToMap(mediaTypeObj.Schema)
+			}
+		}
+		paramInfo["content"] = content
+	}
+	return paramInfo
 }
 
 func (oas *OpenAPIServer) getSpecInfoHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -200,6 +250,17 @@ func (oas *OpenAPIServer) getSpecInfoHandler(ctx context.Context, request mcp.Ca
 		"title":       oas.spec.Info.Title,
 		"version":     oas.spec.Info.Version,
 		"description": oas.spec.Info.Description,
+		"openapi":     oas.spec.OpenAPI,
+	}
+
+	if summary, ok := oas.spec.Info.Extensions["summary"].(string); ok {
+		info["summary"] = summary
+	}
+
+	if !strings.HasPrefix(oas.originalVersion, "openapi ") {
+		// Reported like the openapi field, as the bare version number
+		info["original_version"] = strings.TrimPrefix(oas.originalVersion, "swagger ")
+		info["converted"] = true
 	}
 
 	if oas.spec.Info.Contact != nil {
@@ -211,10 +272,15 @@ func (oas *OpenAPIServer) getSpecInfoHandler(ctx context.Context, request mcp.Ca
 	}
 
 	if oas.spec.Info.License != nil {
-		info["license"] = map[string]string{
+		license := map[string]string{
 			"name": oas.spec.Info.License.Name,
 			"url":  oas.spec.Info.License.URL,
 		}
+		// OpenAPI 3.1 identifies licenses by SPDX expression
+		if identifier, ok := oas.spec.Info.License.Extensions["identifier"].(string); ok {
+			license["identifier"] = identifier
+		}
+		info["license"] = license
 	}
 
 	// Calculate statistics
@@ -229,6 +295,7 @@ func (oas *OpenAPIServer) getSpecInfoHandler(ctx context.Context, request mcp.Ca
 		"paths":      pathCount,
 		"operations": operationCount,
 		"tags":       len(oas.spec.Tags),
+		"webhooks":   len(oas.webhooks),
 	}
 
 	if oas.spec.Servers != nil && len(oas.spec.Servers) > 0 {
@@ -242,9 +309,58 @@ func (oas *OpenAPIServer) getSpecInfoHandler(ctx context.Context, request mcp.Ca
 		info["servers"] = servers
 	}
 
+	if errors, warnings := countIssues(oas.issues); errors > 0 || warnings > 0 {
+		info["validation"] = map[string]int{
+			"errors":   errors,
+			"warnings": warnings,
+		}
+	}
+
+	if status, exists := oas.cache.Status(oas.specSource); exists && status.FromCache {
+		info["cache"] = map[string]interface{}{
+			"cached_at":  status.CachedAt,
+			"expiration": status.Expiration,
+			"stale":      status.Stale,
+		}
+	}
+
+	if oas.spec.Components != nil && len(oas.spec.Components.SecuritySchemes) > 0 {
+		schemes := map[string]interface{}{}
+		for name, schemeRef := range oas.spec.Components.SecuritySchemes {
+			if schemeRef.Value != nil {
+				schemes[name] = securitySchemeToMap(schemeRef.Value, false)
+			}
+		}
+		info["securitySchemes"] = schemes
+	}
+
+	if len(oas.spec.Security) > 0 {
+		info["security"] = oas.securityToMap(oas.spec.Security, "global")
+	}
+
 	return JSONResponse(info)
 }
 
+func (oas *OpenAPIServer) listSecuritySchemesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if oas.spec.Components == nil || len(oas.spec.Components.SecuritySchemes) == 0 {
+		return mcp.NewToolResultText("No security schemes found in the OpenAPI specification"), nil
+	}
+
+	schemes := make([]map[string]interface{}, 0, len(oas.spec.Components.SecuritySchemes))
+	for name, schemeRef := range oas.spec.Components.SecuritySchemes {
+		if schemeRef.Value == nil {
+			continue
+		}
+		scheme := securitySchemeToMap(schemeRef.Value, true)
+		scheme["name"] = name
+		schemes = append(schemes, scheme)
+	}
+
+	SortMapsByName(schemes)
+
+	return JSONResponse(schemes)
+}
+
 func (oas *OpenAPIServer) showSchemaHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	ref, err := request.RequireString("ref")
 	if err != nil {
@@ -254,7 +370,7 @@ func (oas *OpenAPIServer) showSchemaHandler(ctx context.Context, request mcp.Cal
 	// Parse the reference to extract the schema name
 	// Expected format: #/components/schemas/SchemaName
 	if !strings.HasPrefix(ref, "#/components/schemas/") {
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid schema reference format. Expected: #/components/schemas/SchemaName, got: %s", ref)), nil
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid schema reference format. Expected: #/components/schemas/SchemaName, got: %s. Use resolve_ref for other references", ref)), nil
 	}
 
 	schemaName := strings.TrimPrefix(ref, "#/components/schemas/")
@@ -282,6 +398,51 @@ func (oas *OpenAPIServer) showSchemaHandler(ctx context.Context, request mcp.Cal
 	} else if schemaRef.Value != nil {
 		// For individual schema inspection, allow deeper expansion
 		result["schema"] = oas.schemaToMapWithDepth(schemaRef, 0, DetailedSchemaMaxDepth)
+		if request.GetBool("merged", false) && len(schemaRef.Value.AllOf) > 0 {
+			result["merged"] = oas.schemaToMapWithDepth(mergeAllOf(schemaRef), 0, DetailedSchemaMaxDepth)
+		}
+	}
+
+	// Explain polymorphism so the caller knows which concrete subtype to send
+	if schemaRef.Value != nil {
+		variants := oas.discriminatorVariants(schemaName, schemaRef.Value)
+		if schemaRef.Value.Discriminator != nil || len(variants) > 0 {
+			variantList := []map[string]interface{}{}
+			for _, variant := range variants {
+				variantList = append(variantList, map[string]interface{}{
+					"value": variant.Value,
+					"ref":   variant.Ref,
+				})
+			}
+			polymorphism := map[string]interface{}{
+				"variants": variantList,
+			}
+			if schemaRef.Value.Discriminator != nil {
+				polymorphism["propertyName"] = schemaRef.Value.Discriminator.PropertyName
+			}
+			result["polymorphism"] = polymorphism
+		}
+
+		if variantName := request.GetString("variant", ""); variantName != "" {
+			var selected *discriminatorVariant
+			for i, variant := range variants {
+				if variant.Value == variantName || variant.Ref == variantName || refName(variant.Ref) == variantName {
+					selected = &variants[i]
+					break
+				}
+			}
+			if selected == nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Variant not found for schema %s: %s", schemaName, variantName)), nil
+			}
+			if selected.Schema == nil || selected.Schema.Value == nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Variant schema could not be resolved: %s", selected.Ref)), nil
+			}
+			result["variant"] = map[string]interface{}{
+				"value":  selected.Value,
+				"ref":    selected.Ref,
+				"schema": oas.schemaToMapWithDepth(mergeAllOf(selected.Schema), 0, DetailedSchemaMaxDepth),
+			}
+		}
 	}
 
 	return JSONResponse(result)
@@ -292,6 +453,14 @@ func (oas *OpenAPIServer) schemaToMap(schemaRef *openapi3.SchemaRef) map[string]
 	return oas.schemaToMapWithDepth(schemaRef, 0, SchemaMaxDepth)
 }
 
+// contentSchemaToMap renders a request/response body schema, optionally flattening allOf
+func (oas *OpenAPIServer) contentSchemaToMap(schemaRef *openapi3.SchemaRef, merged bool) map[string]interface{} {
+	if merged && schemaRef.Value != nil && len(schemaRef.Value.AllOf) > 0 {
+		return oas.schemaToMap(mergeAllOf(schemaRef))
+	}
+	return oas.schemaToMap(schemaRef)
+}
+
 func (oas *OpenAPIServer) schemaToMapWithDepth(schemaRef *openapi3.SchemaRef, currentDepth, maxDepth int) map[string]interface{} {
 	if schemaRef == nil {
 		return nil
@@ -332,6 +501,9 @@ func (oas *OpenAPIServer) schemaToMapWithDepth(schemaRef *openapi3.SchemaRef, cu
 		result["required"] = schema.Required
 	}
 
+	addSchemaConstraints(result, schema)
+	oas.addJSONSchemaKeywords(result, schema, currentDepth, maxDepth)
+
 	// Only expand properties if we haven't reached max depth
 	if currentDepth < maxDepth {
 		if schema.Properties != nil && len(schema.Properties) > 0 {
@@ -345,6 +517,10 @@ func (oas *OpenAPIServer) schemaToMapWithDepth(schemaRef *openapi3.SchemaRef, cu
 		if schema.Items != nil {
 			result["items"] = oas.schemaToMapWithDepth(schema.Items, currentDepth+1, maxDepth)
 		}
+
+		if schema.AdditionalProperties.Schema != nil {
+			result["additionalProperties"] = oas.schemaToMapWithDepth(schema.AdditionalProperties.Schema, currentDepth+1, maxDepth)
+		}
 	} else {
 		// At max depth, just indicate there's more
 		if schema.Properties != nil && len(schema.Properties) > 0 {
@@ -353,11 +529,129 @@ func (oas *OpenAPIServer) schemaToMapWithDepth(schemaRef *openapi3.SchemaRef, cu
 		if schema.Items != nil {
 			result["items"] = "[not expanded]"
 		}
+		if schema.AdditionalProperties.Schema != nil {
+			result["additionalProperties"] = "[not expanded]"
+		}
+	}
+
+	if schema.AdditionalProperties.Schema == nil && schema.AdditionalProperties.Has != nil {
+		result["additionalProperties"] = *schema.AdditionalProperties.Has
 	}
 
-	if len(schema.Enum) > 0 {
+	// A 3.1 const is also loaded as a single-value enum, so only the const is shown
+	if _, hasConst := result["const"]; !hasConst && len(schema.Enum) > 0 {
 		result["enum"] = schema.Enum
 	}
 
+	if schema.Discriminator != nil {
+		discriminator := map[string]interface{}{
+			"propertyName": schema.Discriminator.PropertyName,
+		}
+		if len(schema.Discriminator.Mapping) > 0 {
+			discriminator["mapping"] = schema.Discriminator.Mapping
+		}
+		result["discriminator"] = discriminator
+	}
+
+	// Composition keywords share the same depth budget as properties
+	for keyword, members := range map[string]openapi3.SchemaRefs{
+		"allOf": schema.AllOf,
+		"oneOf": schema.OneOf,
+		"anyOf": schema.AnyOf,
+	} {
+		if len(members) == 0 {
+			continue
+		}
+		if currentDepth < maxDepth {
+			rendered := make([]map[string]interface{}, 0, len(members))
+			for _, member := range members {
+				rendered = append(rendered, oas.schemaToMapWithDepth(member, currentDepth+1, maxDepth))
+			}
+			result[keyword] = rendered
+		} else {
+			result[keyword] = fmt.Sprintf("[%d schemas not expanded]", len(members))
+		}
+	}
+
+	if schema.Not != nil {
+		if currentDepth < maxDepth {
+			result["not"] = oas.schemaToMapWithDepth(schema.Not, currentDepth+1, maxDepth)
+		} else {
+			result["not"] = "[not expanded]"
+		}
+	}
+
 	return result
 }
+
+// addSchemaConstraints copies validation keywords and modifiers that affect what a valid payload looks like
+func addSchemaConstraints(result map[string]interface{}, schema *openapi3.Schema) {
+	if schema.Title != "" {
+		result["title"] = schema.Title
+	}
+	if schema.Nullable {
+		result["nullable"] = true
+	}
+	if schema.ReadOnly {
+		result["readOnly"] = true
+	}
+	if schema.WriteOnly {
+		result["writeOnly"] = true
+	}
+	if schema.Deprecated {
+		result["deprecated"] = true
+	}
+	if schema.Default != nil {
+		result["default"] = schema.Default
+	}
+	if schema.Example != nil {
+		result["example"] = schema.Example
+	}
+
+	// Number
+	if schema.Min != nil {
+		result["minimum"] = *schema.Min
+		if schema.ExclusiveMin {
+			result["exclusiveMinimum"] = true
+		}
+	}
+	if schema.Max != nil {
+		result["maximum"] = *schema.Max
+		if schema.ExclusiveMax {
+			result["exclusiveMaximum"] = true
+		}
+	}
+	if schema.MultipleOf != nil {
+		result["multipleOf"] = *schema.MultipleOf
+	}
+
+	// String
+	if schema.MinLength > 0 {
+		result["minLength"] = schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		result["maxLength"] = *schema.MaxLength
+	}
+	if schema.Pattern != "" {
+		result["pattern"] = schema.Pattern
+	}
+
+	// Array
+	if schema.MinItems > 0 {
+		result["minItems"] = schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		result["maxItems"] = *schema.MaxItems
+	}
+	if schema.UniqueItems {
+		result["uniqueItems"] = true
+	}
+
+	// Object
+	if schema.MinProps > 0 {
+		result["minProperties"] = schema.MinProps
+	}
+	if schema.MaxProps != nil {
+		result["maxProperties"] = *schema.MaxProps
+	}
+}
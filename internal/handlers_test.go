@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+const orderingSpec = `
+openapi: 3.0.3
+info: {title: ordering, version: "1"}
+paths:
+  /zeta:
+    get: {summary: zeta, responses: {"200": {description: ok}}}
+  /beta/{id}:
+    parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+    put: {summary: update beta, responses: {"200": {description: ok}}}
+  /alpha:
+    delete: {summary: delete alpha, responses: {"204": {description: gone}}}
+    post: {summary: create alpha, responses: {"201": {description: created}}}
+    get: {summary: list alpha, operationId: listAlpha, responses: {"200": {description: ok}}}
+`
+
+type listedEndpoint struct {
+	Path   string `json:"path"`
+	Method string `json:"method"`
+}
+
+type endpointPage struct {
+	Items      []listedEndpoint `json:"items"`
+	Total      int              `json:"total"`
+	NextCursor string           `json:"next_cursor"`
+}
+
+func TestListEndpointsOrder(t *testing.T) {
+	oas := loadTestSpec(t, orderingSpec)
+
+	want := []listedEndpoint{
+		{"/alpha", "GET"},
+		{"/alpha", "POST"},
+		{"/alpha", "DELETE"},
+		{"/beta/{id}", "PUT"},
+		{"/zeta", "GET"},
+	}
+	for i := 0; i < 5; i++ {
+		var got endpointPage
+		callToolJSON(t, oas.listEndpointsHandler, nil, &got)
+		if !reflect.DeepEqual(got.Items, want) {
+			t.Fatalf("call %d: got %v, want %v", i, got.Items, want)
+		}
+		// Without a limit everything fits in one page
+		if got.Total != len(want) || got.NextCursor != "" {
+			t.Fatalf("call %d: total = %d, next_cursor = %q", i, got.Total, got.NextCursor)
+		}
+	}
+}
+
+func TestListEndpointsCursorRoundTrip(t *testing.T) {
+	oas := loadTestSpec(t, orderingSpec)
+
+	var unpaged endpointPage
+	callToolJSON(t, oas.listEndpointsHandler, nil, &unpaged)
+	all := unpaged.Items
+
+	var paged []listedEndpoint
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > len(all) {
+			t.Fatal("pagination did not terminate")
+		}
+		args := map[string]interface{}{"limit": float64(2)}
+		if cursor != "" {
+			args["cursor"] = cursor
+		}
+		var page endpointPage
+		callToolJSON(t, oas.listEndpointsHandler, args, &page)
+		if page.Total != len(all) {
+			t.Fatalf("total = %d, want %d", page.Total, len(all))
+		}
+		if len(page.Items) == 0 || len(page.Items) > 2 {
+			t.Fatalf("page %d has %d items", pages, len(page.Items))
+		}
+		paged = append(paged, page.Items...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if !reflect.DeepEqual(paged, all) {
+		t.Fatalf("paged %v, want %v", paged, all)
+	}
+}
+
+func TestListEndpointsInvalidCursor(t *testing.T) {
+	oas := loadTestSpec(t, orderingSpec)
+
+	if text, isError := callTool(t, oas.listEndpointsHandler, map[string]interface{}{"cursor": "not-a-cursor"}); !isError {
+		t.Fatalf("expected an error, got %s", text)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, offset := range []int{0, 1, 2, 50, 12345} {
+		got, err := DecodeCursor(EncodeCursor(offset))
+		if err != nil || got != offset {
+			t.Errorf("DecodeCursor(EncodeCursor(%d)) = %d, %v", offset, got, err)
+		}
+	}
+	if _, err := DecodeCursor(EncodeCursor(0)[:2]); err == nil {
+		t.Error("expected an error for a truncated cursor")
+	}
+}
+
+func TestPaginateBounds(t *testing.T) {
+	items := []map[string]interface{}{{"name": "a"}, {"name": "b"}, {"name": "c"}}
+
+	page := Paginate(items, 1, 1)
+	if got := page["items"].([]map[string]interface{}); len(got) != 1 || got[0]["name"] != "b" {
+		t.Fatalf("items = %v", got)
+	}
+	if page["next_cursor"] != EncodeCursor(2) {
+		t.Fatalf("next_cursor = %v", page["next_cursor"])
+	}
+
+	page = Paginate(items, 10, 2)
+	if got := page["items"].([]map[string]interface{}); len(got) != 0 {
+		t.Fatalf("items past the end = %v", got)
+	}
+	if _, exists := page["next_cursor"]; exists {
+		t.Fatal("unexpected next_cursor past the end")
+	}
+}
+
+func TestShowSchemaConstraints(t *testing.T) {
+	oas := loadTestSpec(t, `
+openapi: 3.0.3
+info: {title: constraints, version: "1"}
+paths: {}
+components:
+  schemas:
+    Order:
+      type: object
+      minProperties: 1
+      maxProperties: 5
+      properties:
+        amount: {type: number, minimum: 0, exclusiveMinimum: true, maximum: 1000, multipleOf: 0.01}
+        quantity: {type: integer, minimum: 1, maximum: 99}
+        code: {type: string, minLength: 3, maxLength: 8, pattern: "^[A-Z]+$"}
+        tags: {type: array, minItems: 1, maxItems: 4, uniqueItems: true, items: {type: string}}
+        note: {type: string, title: Note, nullable: true, readOnly: true, deprecated: true, default: none, example: fragile}
+`)
+
+	var result struct {
+		Schema struct {
+			MinProperties float64                           `json:"minProperties"`
+			MaxProperties float64                           `json:"maxProperties"`
+			Properties    map[string]map[string]interface{} `json:"properties"`
+		} `json:"schema"`
+	}
+	callToolJSON(t, oas.showSchemaHandler, map[string]interface{}{"ref": "#/components/schemas/Order"}, &result)
+	if result.Schema.MinProperties != 1 || result.Schema.MaxProperties != 5 {
+		t.Errorf("properties bounds are %v..%v, want 1..5", result.Schema.MinProperties, result.Schema.MaxProperties)
+	}
+
+	want := map[string]map[string]interface{}{
+		"amount":   {"minimum": 0.0, "exclusiveMinimum": true, "maximum": 1000.0, "multipleOf": 0.01},
+		"quantity": {"minimum": 1.0, "maximum": 99.0},
+		"code":     {"minLength": 3.0, "maxLength": 8.0, "pattern": "^[A-Z]+$"},
+		"tags":     {"minItems": 1.0, "maxItems": 4.0, "uniqueItems": true},
+		"note":     {"title": "Note", "nullable": true, "readOnly": true, "deprecated": true, "default": "none", "example": "fragile"},
+	}
+	for name, constraints := range want {
+		property := result.Schema.Properties[name]
+		for key, value := range constraints {
+			if property[key] != value {
+				t.Errorf("%s.%s = %v, want %v", name, key, property[key], value)
+			}
+		}
+	}
+
+	// Unset and inclusive constraints are left out
+	quantity := result.Schema.Properties["quantity"]
+	for _, key := range []string{"exclusiveMinimum", "exclusiveMaximum", "multipleOf", "minLength", "pattern", "uniqueItems"} {
+		if value, exists := quantity[key]; exists {
+			t.Errorf("quantity.%s = %v, want it left out", key, value)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// loadTestSpec loads a spec written to a temporary file
+func loadTestSpec(t *testing.T, document string) *OpenAPIServer {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(path, []byte(document), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	oas := NewOpenAPIServer(path, t.TempDir())
+	if err := oas.LoadSpec(); err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	return oas
+}
+
+// loadTestSpecFiles loads openapi.yaml from a temporary directory holding the given files, for
+// specs referencing other documents
+func loadTestSpecFiles(t *testing.T, files map[string]string) *OpenAPIServer {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oas := NewOpenAPIServer(filepath.Join(dir, "openapi.yaml"), t.TempDir())
+	if err := oas.LoadSpec(); err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	return oas
+}
+
+// callTool runs a tool handler and returns the text it produced and whether it reported an error
+func callTool(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]interface{}) (string, bool) {
+	t.Helper()
+	result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+	if err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	text, ok := mcp.AsTextContent(result.Content[0])
+	if !ok {
+		t.Fatalf("expected text content, got %T", result.Content[0])
+	}
+	return text.Text, result.IsError
+}
+
+// callToolJSON runs a tool handler that must succeed and decodes its JSON output
+func callToolJSON(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]interface{}, out interface{}) {
+	t.Helper()
+	text, isError := callTool(t, handler, args)
+	if isError {
+		t.Fatalf("tool returned an error: %s", text)
+	}
+	if err := json.Unmarshal([]byte(text), out); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, text)
+	}
+}
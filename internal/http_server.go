@@ -1,15 +1,20 @@
 package internal
 
 import (
+	"context"
 	"log"
 
 	"github.com/mark3labs/mcp-go/server"
 )
 
 // StartHTTPServer starts the HTTP server using the built-in StreamableHTTPServer
-func StartHTTPServer(oas *OpenAPIServer, addr string) error {
+func StartHTTPServer(registry *SpecRegistry, addr string) error {
 	// Create MCP server instance with the same tools as stdio
-	mcpServer := CreateMCPServerWithTools(oas)
+	mcpServer := CreateMCPServerWithTools(registry)
+
+	if interval := GetReloadInterval(); interval > 0 {
+		go WatchSpecs(context.Background(), registry, mcpServer, interval)
+	}
 
 	// Create HTTP server with StreamableHTTPServer
 	httpServer := server.NewStreamableHTTPServer(mcpServer)
@@ -6,15 +6,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
-func RunInteractiveMode(oas *OpenAPIServer) {
+func RunInteractiveMode(registry *SpecRegistry) {
 	scanner := bufio.NewScanner(os.Stdin)
 	ctx := context.Background()
 
+	oas := selectSpec(registry, scanner)
+	if oas == nil {
+		return
+	}
+
 	for {
 		displayMenu()
 
@@ -23,7 +29,7 @@ func RunInteractiveMode(oas *OpenAPIServer) {
 		}
 
 		choice := strings.TrimSpace(scanner.Text())
-		if choice == "6" {
+		if choice == "18" {
 			fmt.Println("Exiting...")
 			return
 		}
@@ -32,6 +38,32 @@ func RunInteractiveMode(oas *OpenAPIServer) {
 	}
 }
 
+// selectSpec asks which spec to explore when more than one is loaded
+func selectSpec(registry *SpecRegistry, scanner *bufio.Scanner) *OpenAPIServer {
+	servers := registry.Servers()
+	if len(servers) == 1 {
+		return servers[0]
+	}
+
+	for {
+		fmt.Println("\n=== OpenAPI Specs ===")
+		for i, oas := range servers {
+			fmt.Printf("%d. %s (%s)\n", i+1, oas.name, oas.spec.Info.Title)
+		}
+		fmt.Printf("\nSelect a spec (1-%d): ", len(servers))
+
+		if !scanner.Scan() {
+			return nil
+		}
+
+		choice, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+		if err == nil && choice >= 1 && choice <= len(servers) {
+			return servers[choice-1]
+		}
+		fmt.Printf("Invalid choice. Please select 1-%d.\n", len(servers))
+	}
+}
+
 func displayMenu() {
 	fmt.Println("\n=== OpenAPI Tools ===")
 	fmt.Println("1. List Categories")
@@ -39,8 +71,20 @@ func displayMenu() {
 	fmt.Println("3. Show Endpoint Details")
 	fmt.Println("4. Get Spec Info")
 	fmt.Println("5. Show Schema Details")
-	fmt.Println("6. Exit")
-	fmt.Print("\nSelect a tool (1-6): ")
+	fmt.Println("6. List Security Schemes")
+	fmt.Println("7. Resolve Reference")
+	fmt.Println("8. Search")
+	fmt.Println("9. List Webhooks")
+	fmt.Println("10. Show Webhook Details")
+	fmt.Println("11. Validate Spec")
+	fmt.Println("12. Generate Example")
+	fmt.Println("13. Build Request")
+	fmt.Println("14. Validate Request")
+	fmt.Println("15. Validate Response")
+	fmt.Println("16. Match Request")
+	fmt.Println("17. Call Endpoint")
+	fmt.Println("18. Exit")
+	fmt.Print("\nSelect a tool (1-18): ")
 }
 
 func handleUserChoice(ctx context.Context, choice string, oas *OpenAPIServer, scanner *bufio.Scanner) {
@@ -109,8 +153,294 @@ func handleUserChoice(ctx context.Context, choice string, oas *OpenAPIServer, sc
 		result, err := oas.showSchemaHandler(ctx, req)
 		printResult(result, err)
 
+	case "6":
+		result, err := oas.listSecuritySchemesHandler(ctx, mcp.CallToolRequest{})
+		printResult(result, err)
+
+	case "7":
+		fmt.Print("Enter reference (e.g., #/components/parameters/PageSize): ")
+		scanner.Scan()
+		ref := strings.TrimSpace(scanner.Text())
+
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "resolve_ref",
+				Arguments: map[string]interface{}{
+					"ref": ref,
+				},
+			},
+		}
+
+		result, err := oas.resolveRefHandler(ctx, req)
+		printResult(result, err)
+
+	case "8":
+		fmt.Print("Enter search query: ")
+		scanner.Scan()
+		query := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter kind (operation, schema, parameter, tag, or press Enter for all): ")
+		scanner.Scan()
+		kind := strings.TrimSpace(scanner.Text())
+
+		args := map[string]interface{}{"query": query}
+		if kind != "" {
+			args["kind"] = kind
+		}
+
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "search",
+				Arguments: args,
+			},
+		}
+
+		result, err := oas.searchHandler(ctx, req)
+		printResult(result, err)
+
+	case "9":
+		result, err := oas.listWebhooksHandler(ctx, mcp.CallToolRequest{})
+		printResult(result, err)
+
+	case "10":
+		fmt.Print("Enter webhook name: ")
+		scanner.Scan()
+		name := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter method (or press Enter for the only operation): ")
+		scanner.Scan()
+		method := strings.TrimSpace(scanner.Text())
+
+		args := map[string]interface{}{"name": name}
+		if method != "" {
+			args["method"] = method
+		}
+
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "show_webhook",
+				Arguments: args,
+			},
+		}
+
+		result, err := oas.showWebhookHandler(ctx, req)
+		printResult(result, err)
+
+	case "11":
+		result, err := oas.validateSpecHandler(ctx, mcp.CallToolRequest{})
+		printResult(result, err)
+
+	case "12":
+		fmt.Print("Enter path (e.g., /users/{id}): ")
+		scanner.Scan()
+		path := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter method (GET, POST, PUT, DELETE, etc.): ")
+		scanner.Scan()
+		method := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter response status (or press Enter for the request body): ")
+		scanner.Scan()
+		status := strings.TrimSpace(scanner.Text())
+
+		args := map[string]interface{}{
+			"path":   path,
+			"method": method,
+		}
+		if status != "" {
+			args["target"] = exampleTargetResponse
+			args["status"] = status
+		}
+
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "generate_example",
+				Arguments: args,
+			},
+		}
+
+		result, err := oas.generateExampleHandler(ctx, req)
+		printResult(result, err)
+
+	case "13":
+		fmt.Print("Enter path (e.g., /users/{id}): ")
+		scanner.Scan()
+		path := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter method (GET, POST, PUT, DELETE, etc.): ")
+		scanner.Scan()
+		method := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter parameters as JSON (or press Enter for none): ")
+		scanner.Scan()
+		parameters := strings.TrimSpace(scanner.Text())
+
+		args := map[string]interface{}{
+			"path":   path,
+			"method": method,
+		}
+		if parameters != "" {
+			var values map[string]interface{}
+			if err := json.Unmarshal([]byte(parameters), &values); err != nil {
+				fmt.Printf("\nError: invalid parameters JSON: %v\n", err)
+				return
+			}
+			args["parameters"] = values
+		}
+
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "build_request",
+				Arguments: args,
+			},
+		}
+
+		result, err := oas.buildRequestHandler(ctx, req)
+		printResult(result, err)
+
+	case "14":
+		fmt.Print("Enter path (e.g., /users/{id}): ")
+		scanner.Scan()
+		path := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter method (GET, POST, PUT, DELETE, etc.): ")
+		scanner.Scan()
+		method := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter parameters as JSON (or press Enter for none): ")
+		scanner.Scan()
+		parameters := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter body as JSON (or press Enter for none): ")
+		scanner.Scan()
+		body := strings.TrimSpace(scanner.Text())
+
+		args := map[string]interface{}{
+			"path":   path,
+			"method": method,
+		}
+		if parameters != "" {
+			var values map[string]interface{}
+			if err := json.Unmarshal([]byte(parameters), &values); err != nil {
+				fmt.Printf("\nError: invalid parameters JSON: %v\n", err)
+				return
+			}
+			args["parameters"] = values
+		}
+		if body != "" {
+			var value interface{}
+			if err := json.Unmarshal([]byte(body), &value); err != nil {
+				fmt.Printf("\nError: invalid body JSON: %v\n", err)
+				return
+			}
+			args["body"] = value
+		}
+
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "validate_request",
+				Arguments: args,
+			},
+		}
+
+		result, err := oas.validateRequestHandler(ctx, req)
+		printResult(result, err)
+
+	case "15":
+		fmt.Print("Enter path (e.g., /users/{id}): ")
+		scanner.Scan()
+		path := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter method (GET, POST, PUT, DELETE, etc.): ")
+		scanner.Scan()
+		method := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter status code (e.g., 200): ")
+		scanner.Scan()
+		status, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+		if err != nil {
+			fmt.Println("\nError: invalid status code")
+			return
+		}
+
+		fmt.Print("Enter body (or press Enter for none): ")
+		scanner.Scan()
+		body := strings.TrimSpace(scanner.Text())
+
+		args := map[string]interface{}{
+			"path":   path,
+			"method": method,
+			"status": float64(status),
+		}
+		if body != "" {
+			args["body"] = body
+		}
+
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "validate_response",
+				Arguments: args,
+			},
+		}
+
+		result, err := oas.validateResponseHandler(ctx, req)
+		printResult(result, err)
+
+	case "16":
+		fmt.Print("Enter URL (e.g., GET https://api.example.com/users/42): ")
+		scanner.Scan()
+		rawURL := strings.TrimSpace(scanner.Text())
+
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "match_request",
+				Arguments: map[string]interface{}{
+					"url": rawURL,
+				},
+			},
+		}
+
+		result, err := oas.matchRequestHandler(ctx, req)
+		printResult(result, err)
+
+	case "17":
+		fmt.Print("Enter path (e.g., /users/{id}): ")
+		scanner.Scan()
+		path := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter method (GET, POST, PUT, DELETE, etc.): ")
+		scanner.Scan()
+		method := strings.TrimSpace(scanner.Text())
+
+		fmt.Print("Enter parameters as JSON (or press Enter for none): ")
+		scanner.Scan()
+		parameters := strings.TrimSpace(scanner.Text())
+
+		args := map[string]interface{}{
+			"path":   path,
+			"method": method,
+		}
+		if parameters != "" {
+			var values map[string]interface{}
+			if err := json.Unmarshal([]byte(parameters), &values); err != nil {
+				fmt.Printf("\nError: invalid parameters JSON: %v\n", err)
+				return
+			}
+			args["parameters"] = values
+		}
+
+		req := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "call_endpoint",
+				Arguments: args,
+			},
+		}
+
+		result, err := oas.callEndpointHandler(ctx, req)
+		printResult(result, err)
+
 	default:
-		fmt.Println("Invalid choice. Please select 1-6.")
+		fmt.Println("Invalid choice. Please select 1-18.")
 	}
 }
 
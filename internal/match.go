@@ -0,0 +1,427 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy/pathpattern"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// buildRouter indexes the operations by method and path template the way routers/legacy does,
+// without its requirement that the spec passes validation
+func buildRouter(spec *openapi3.T) *pathpattern.Node {
+	root := &pathpattern.Node{}
+	for _, entry := range sortedOperations(spec) {
+		route := &routers.Route{
+			Spec:      spec,
+			Path:      entry.Path,
+			PathItem:  entry.PathItem,
+			Method:    entry.Method,
+			Operation: entry.Operation,
+		}
+		if err := root.Add(entry.Method+" "+entry.Path, route, nil); err != nil {
+			log.Printf("Path %s cannot be matched against URLs: %v", entry.Path, err)
+		}
+	}
+	return root
+}
+
+// serverMatch is a way to split a URL into a server and the path below it
+type serverMatch struct {
+	Server    *openapi3.Server
+	Variables map[string]string
+	Remaining string
+	// ByPath is set when only the base path of the server matched, not its host
+	ByPath bool
+}
+
+// serverPathPattern returns the path part of a server URL, keeping its variables
+func serverPathPattern(serverURL string) string {
+	if _, rest, found := strings.Cut(serverURL, "://"); found {
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			return rest[i:]
+		}
+		return "/"
+	}
+	return serverURL
+}
+
+// allServers lists the servers of the spec, its paths and its operations, without duplicates
+func allServers(spec *openapi3.T) openapi3.Servers {
+	servers := openapi3.Servers{}
+	seen := map[string]bool{}
+	add := func(candidates openapi3.Servers) {
+		for _, server := range candidates {
+			if server != nil && !seen[server.URL] {
+				seen[server.URL] = true
+				servers = append(servers, server)
+			}
+		}
+	}
+	add(spec.Servers)
+	for _, entry := range sortedOperations(spec) {
+		add(entry.PathItem.Servers)
+		if entry.Operation.Servers != nil {
+			add(*entry.Operation.Servers)
+		}
+	}
+	return servers
+}
+
+// serverMatches lists the ways the URL can be split into a server and a path, best first: full
+// server URLs, then server base paths alone, then the URL path as is
+func serverMatches(servers openapi3.Servers, u *url.URL) []serverMatch {
+	matches := []serverMatch{}
+	try := func(server *openapi3.Server, pattern, input string, byPath bool) {
+		values, remaining, ok := openapi3.Server{URL: pattern}.MatchRawURL(input)
+		if !ok {
+			return
+		}
+		names, err := openapi3.Server{URL: pattern}.ParameterNames()
+		if err != nil || len(names) != len(values) {
+			return
+		}
+		variables := map[string]string{}
+		for i, name := range names {
+			variables[name] = values[i]
+		}
+		matches = append(matches, serverMatch{Server: server, Variables: variables, Remaining: remaining, ByPath: byPath})
+	}
+
+	if u.IsAbs() {
+		full := u.Scheme + "://" + u.Host + u.EscapedPath()
+		for _, server := range servers {
+			if isURL(server.URL) {
+				try(server, server.URL, full, false)
+			}
+		}
+	}
+	for _, server := range servers {
+		try(server, serverPathPattern(server.URL), u.EscapedPath(), u.IsAbs() && isURL(server.URL))
+	}
+	matches = append(matches, serverMatch{Remaining: u.EscapedPath()})
+	return matches
+}
+
+// decodePrimitive converts a raw parameter value to the schema's type, keeping it as a string when
+// it does not parse
+func decodePrimitive(schema *openapi3.Schema, raw string) interface{} {
+	if schema == nil || schema.Type == nil {
+		return raw
+	}
+	switch {
+	case schema.Type.Is(openapi3.TypeInteger):
+		if value, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return value
+		}
+	case schema.Type.Is(openapi3.TypeNumber):
+		if value, err := strconv.ParseFloat(raw, 64); err == nil {
+			return value
+		}
+	case schema.Type.Is(openapi3.TypeBoolean):
+		if value, err := strconv.ParseBool(raw); err == nil {
+			return value
+		}
+	}
+	return raw
+}
+
+// decodeItems turns raw items into an array or object value for the schema. Objects are written as
+// key=value pairs when exploded and as alternating keys and values otherwise.
+func decodeItems(schema *openapi3.Schema, items []string, explode bool) interface{} {
+	if schema != nil && schema.Type != nil && schema.Type.Is(openapi3.TypeObject) {
+		object := map[string]interface{}{}
+		if explode {
+			for _, item := range items {
+				key, value, _ := strings.Cut(item, "=")
+				object[key] = decodeProperty(schema, key, value)
+			}
+		} else {
+			for i := 0; i+1 < len(items); i += 2 {
+				object[items[i]] = decodeProperty(schema, items[i], items[i+1])
+			}
+		}
+		return object
+	}
+
+	if schema != nil && schema.Type != nil && schema.Type.Is(openapi3.TypeArray) {
+		var itemSchema *openapi3.Schema
+		if schema.Items != nil {
+			itemSchema = schema.Items.Value
+		}
+		values := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			values = append(values, decodePrimitive(itemSchema, item))
+		}
+		return values
+	}
+
+	return decodePrimitive(schema, strings.Join(items, ","))
+}
+
+func decodeProperty(schema *openapi3.Schema, name, raw string) interface{} {
+	if prop := schema.Properties[name]; prop != nil {
+		return decodePrimitive(prop.Value, raw)
+	}
+	return raw
+}
+
+// decodePathParameter decodes a path segment following the parameter's style
+func decodePathParameter(param *openapi3.Parameter, raw string) interface{} {
+	method, err := param.SerializationMethod()
+	if err != nil {
+		return raw
+	}
+	schema := mergeAllOf(param.Schema)
+	var value *openapi3.Schema
+	if schema != nil {
+		value = schema.Value
+	}
+
+	unescape := func(items []string) []string {
+		for i, item := range items {
+			if unescaped, err := url.PathUnescape(item); err == nil {
+				items[i] = unescaped
+			}
+		}
+		return items
+	}
+
+	switch method.Style {
+	case openapi3.SerializationLabel:
+		raw = strings.TrimPrefix(raw, ".")
+		if method.Explode {
+			return decodeItems(value, unescape(strings.Split(raw, ".")), true)
+		}
+		return decodeItems(value, unescape(strings.Split(raw, ",")), false)
+	case openapi3.SerializationMatrix:
+		raw = strings.TrimPrefix(raw, ";")
+		if method.Explode {
+			items := strings.Split(raw, ";")
+			isObject := value != nil && value.Type != nil && value.Type.Is(openapi3.TypeObject)
+			if !isObject {
+				for i, item := range items {
+					items[i] = strings.TrimPrefix(item, param.Name+"=")
+				}
+			}
+			return decodeItems(value, unescape(items), true)
+		}
+		raw = strings.TrimPrefix(raw, param.Name+"=")
+		return decodeItems(value, unescape(strings.Split(raw, ",")), false)
+	default:
+		return decodeItems(value, unescape(strings.Split(raw, ",")), method.Explode)
+	}
+}
+
+// decodeQueryParameter reads a query parameter following its style. It reports the query keys it
+// used so the rest can be listed as undocumented.
+func decodeQueryParameter(param *openapi3.Parameter, query url.Values, claimed map[string]bool) (interface{}, bool) {
+	method, err := param.SerializationMethod()
+	if err != nil {
+		return nil, false
+	}
+	schema := mergeAllOf(param.Schema)
+	var value *openapi3.Schema
+	if schema != nil {
+		value = schema.Value
+	}
+	isObject := value != nil && value.Type != nil && value.Type.Is(openapi3.TypeObject)
+	isArray := value != nil && value.Type != nil && value.Type.Is(openapi3.TypeArray)
+
+	switch {
+	case method.Style == openapi3.SerializationDeepObject:
+		object := map[string]interface{}{}
+		prefix := param.Name + "["
+		for _, key := range sortedKeys(query) {
+			if strings.HasPrefix(key, prefix) && strings.HasSuffix(key, "]") {
+				name := key[len(prefix) : len(key)-1]
+				object[name] = decodeProperty(value, name, query.Get(key))
+				claimed[key] = true
+			}
+		}
+		return object, len(object) > 0
+
+	case isObject && method.Explode:
+		// Exploded form objects spread their properties over the query, so only declared ones are taken
+		object := map[string]interface{}{}
+		for name := range value.Properties {
+			if values, exists := query[name]; exists && !claimed[name] {
+				object[name] = decodeProperty(value, name, values[0])
+				claimed[name] = true
+			}
+		}
+		return object, len(object) > 0
+	}
+
+	values, exists := query[param.Name]
+	if !exists {
+		return nil, false
+	}
+	claimed[param.Name] = true
+
+	if isArray || isObject {
+		if method.Explode && isArray {
+			return decodeItems(value, values, true), true
+		}
+		delimiter := ","
+		switch method.Style {
+		case openapi3.SerializationSpaceDelimited:
+			delimiter = " "
+		case openapi3.SerializationPipeDelimited:
+			delimiter = "|"
+		}
+		return decodeItems(value, strings.Split(values[0], delimiter), false), true
+	}
+	if param.Schema == nil && len(param.Content) > 0 {
+		return values[0], true
+	}
+	return decodePrimitive(value, values[0]), true
+}
+
+// matchURL finds the operation serving a concrete URL
+func (oas *OpenAPIServer) matchURL(method string, u *url.URL) (serverMatch, *routers.Route, map[string]string, error) {
+	var allowed []string
+	for _, match := range serverMatches(allServers(oas.spec), u) {
+		if node, values := oas.router.Match(method + " " + match.Remaining); node != nil {
+			route, _ := node.Value.(*routers.Route)
+			if route == nil || len(values) != len(node.VariableNames) {
+				continue
+			}
+			params := map[string]string{}
+			for i, name := range node.VariableNames {
+				params[name] = values[i]
+			}
+			return match, route, params, nil
+		}
+		if allowed == nil {
+			for _, candidate := range methodOrder {
+				if candidate != method {
+					if node, _ := oas.router.Match(candidate + " " + match.Remaining); node != nil && node.Value != nil {
+						allowed = append(allowed, candidate)
+					}
+				}
+			}
+		}
+	}
+
+	if len(allowed) > 0 {
+		return serverMatch{}, nil, nil, fmt.Errorf("No %s operation matches %s. The path supports: %s", method, u, strings.Join(allowed, ", "))
+	}
+	return serverMatch{}, nil, nil, fmt.Errorf("No operation matches %s %s", method, u)
+}
+
+func (oas *OpenAPIServer) matchRequestHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	rawURL, err := request.RequireString("url")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	rawURL = strings.TrimSpace(rawURL)
+
+	// Log lines often read "GET https://..."
+	method := strings.ToUpper(request.GetString("method", ""))
+	if prefix, rest, found := strings.Cut(rawURL, " "); found && !strings.Contains(prefix, "/") {
+		if method == "" {
+			method = strings.ToUpper(prefix)
+		}
+		rawURL = strings.TrimSpace(rest)
+	}
+	if method == "" {
+		method = "GET"
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid URL: %v", err)), nil
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	match, route, rawParams, err := oas.matchURL(method, u)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := map[string]interface{}{
+		"method":  method,
+		"path":    route.Path,
+		"summary": route.Operation.Summary,
+	}
+	if route.Operation.OperationID != "" {
+		result["operationId"] = route.Operation.OperationID
+	}
+	warnings := []string{}
+	if match.Server != nil {
+		result["server"] = match.Server.URL
+		if len(match.Variables) > 0 {
+			result["server_variables"] = match.Variables
+		}
+		if match.ByPath {
+			warnings = append(warnings, fmt.Sprintf("the host %s is not one of the spec's servers, the URL was matched by the base path of %s", u.Host, match.Server.URL))
+		}
+	} else if len(allServers(oas.spec)) > 0 {
+		warnings = append(warnings, "no server base path matches the URL, its path was matched as is")
+	}
+
+	pathParams := map[string]interface{}{}
+	queryParams := map[string]interface{}{}
+	missing := []string{}
+	query := u.Query()
+	claimed := map[string]bool{}
+	// API keys sent in the query are documented by the security schemes
+	requirements, _ := effectiveSecurity(oas.spec, route.Operation)
+	for _, requirement := range requirements {
+		for name := range requirement {
+			if scheme := oas.securityScheme(name); scheme != nil && scheme.Type == "apiKey" && scheme.In == openapi3.ParameterInQuery {
+				claimed[scheme.Name] = true
+			}
+		}
+	}
+	for _, declared := range effectiveParameters(route.PathItem, route.Operation) {
+		param := declared.Ref.Value
+		switch param.In {
+		case openapi3.ParameterInPath:
+			if raw, exists := rawParams[param.Name]; exists {
+				pathParams[param.Name] = decodePathParameter(param, raw)
+			}
+		case openapi3.ParameterInQuery:
+			if value, found := decodeQueryParameter(param, query, claimed); found {
+				queryParams[param.Name] = value
+			} else if param.Required {
+				missing = append(missing, param.Name)
+			}
+		}
+	}
+	result["path_parameters"] = pathParams
+	result["query_parameters"] = queryParams
+
+	undocumented := map[string]interface{}{}
+	for _, key := range sortedKeys(query) {
+		if claimed[key] {
+			continue
+		}
+		if len(query[key]) == 1 {
+			undocumented[key] = query[key][0]
+		} else {
+			undocumented[key] = query[key]
+		}
+	}
+	if len(undocumented) > 0 {
+		result["undocumented_query"] = undocumented
+	}
+	if len(missing) > 0 {
+		result["missing_required_query"] = missing
+	}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+
+	return JSONResponse(result)
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const matchSpec = `
+openapi: 3.0.3
+info: {title: match, version: "1"}
+servers:
+  - url: https://api.example.com/v1
+  - url: https://{region}.example.com/{version}
+    variables:
+      region: {default: eu}
+      version: {default: v2}
+paths:
+  /users/{id}/orders:
+    get:
+      operationId: listUserOrders
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+        - {name: status, in: query, schema: {type: array, items: {type: string}}}
+        - {name: ids, in: query, explode: false, schema: {type: array, items: {type: integer}}}
+      responses: {"200": {description: ok}}
+  /users/me/orders:
+    get:
+      operationId: listMyOrders
+      responses: {"200": {description: ok}}
+    post:
+      operationId: createMyOrder
+      responses: {"201": {description: created}}
+`
+
+type matchedRequest struct {
+	Path            string                 `json:"path"`
+	OperationID     string                 `json:"operationId"`
+	Server          string                 `json:"server"`
+	ServerVariables map[string]string      `json:"server_variables"`
+	PathParameters  map[string]interface{} `json:"path_parameters"`
+	QueryParameters map[string]interface{} `json:"query_parameters"`
+	Warnings        []string               `json:"warnings"`
+}
+
+func matchTestURL(t *testing.T, oas *OpenAPIServer, rawURL string) matchedRequest {
+	t.Helper()
+	var result matchedRequest
+	callToolJSON(t, oas.matchRequestHandler, map[string]interface{}{"url": rawURL}, &result)
+	return result
+}
+
+func TestMatchRequestStripsServerBasePath(t *testing.T) {
+	oas := loadTestSpec(t, matchSpec)
+
+	result := matchTestURL(t, oas, "https://api.example.com/v1/users/42/orders")
+	if result.Path != "/users/{id}/orders" || result.Server != "https://api.example.com/v1" {
+		t.Fatalf("got path %s on server %s", result.Path, result.Server)
+	}
+	if got := result.PathParameters["id"]; got != float64(42) {
+		t.Errorf("id = %v, want 42", got)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", result.Warnings)
+	}
+
+	// Server variables are extracted from a templated server URL
+	result = matchTestURL(t, oas, "https://us.example.com/v3/users/7/orders")
+	if result.Path != "/users/{id}/orders" || result.Server != "https://{region}.example.com/{version}" {
+		t.Fatalf("got path %s on server %s", result.Path, result.Server)
+	}
+	if want := map[string]string{"region": "us", "version": "v3"}; !reflect.DeepEqual(result.ServerVariables, want) {
+		t.Errorf("server variables = %v, want %v", result.ServerVariables, want)
+	}
+
+	// A bare path is matched below a server base path too
+	if result = matchTestURL(t, oas, "/v1/users/1/orders"); result.Path != "/users/{id}/orders" {
+		t.Errorf("bare path matched %s", result.Path)
+	}
+}
+
+func TestMatchRequestPrefersLiteralPath(t *testing.T) {
+	oas := loadTestSpec(t, matchSpec)
+
+	if result := matchTestURL(t, oas, "https://api.example.com/v1/users/me/orders"); result.OperationID != "listMyOrders" {
+		t.Errorf("matched %s %s, want the literal /users/me/orders", result.OperationID, result.Path)
+	}
+	if result := matchTestURL(t, oas, "https://api.example.com/v1/users/you/orders"); result.OperationID != "listUserOrders" {
+		t.Errorf("matched %s %s, want the templated /users/{id}/orders", result.OperationID, result.Path)
+	}
+}
+
+func TestMatchRequestDecodesArrayQuery(t *testing.T) {
+	oas := loadTestSpec(t, matchSpec)
+
+	result := matchTestURL(t, oas, "https://api.example.com/v1/users/42/orders?status=open&status=paid&ids=1,2,3")
+	want := map[string]interface{}{
+		"status": []interface{}{"open", "paid"},
+		"ids":    []interface{}{float64(1), float64(2), float64(3)},
+	}
+	if !reflect.DeepEqual(result.QueryParameters, want) {
+		t.Errorf("query parameters = %v, want %v", result.QueryParameters, want)
+	}
+}
+
+func TestMatchRequestMethodMismatch(t *testing.T) {
+	oas := loadTestSpec(t, matchSpec)
+
+	text, isError := callTool(t, oas.matchRequestHandler, map[string]interface{}{"url": "DELETE https://api.example.com/v1/users/me/orders"})
+	if !isError {
+		t.Fatalf("a DELETE matched: %s", text)
+	}
+	if !strings.Contains(text, "No DELETE operation matches") || !strings.Contains(text, "The path supports: GET, POST") {
+		t.Errorf("error does not list the supported methods: %s", text)
+	}
+}
@@ -0,0 +1,256 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const DefaultMockPort = ":4010"
+
+// mockPreferences are the choices a client can make with the Prefer header, e.g.
+// "Prefer: code=404, example=notFound"
+type mockPreferences struct {
+	Code    string
+	Example string
+}
+
+func parsePrefer(header []string) mockPreferences {
+	preferences := mockPreferences{}
+	for _, value := range header {
+		for _, token := range strings.Split(value, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(token), "=")
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "code":
+				preferences.Code = value
+			case "example":
+				preferences.Example = value
+			}
+		}
+	}
+	return preferences
+}
+
+// responseStatus returns the status code to send for a documented response key. Ranges send their
+// first code and default sends 200.
+func responseStatus(key string) int {
+	if len(key) == 3 && strings.HasSuffix(strings.ToUpper(key), "XX") {
+		key = key[:1] + "00"
+	}
+	if status, err := strconv.Atoi(key); err == nil {
+		return status
+	}
+	return http.StatusOK
+}
+
+// preferredResponse picks the response a Prefer code asks for, with the key its status is sent
+// with. A range such as 4XX picks the lowest documented code in the range, else the range itself
+// or default. A range or default response asked for by an exact code is sent with that code.
+func preferredResponse(operation *openapi3.Operation, code string) (string, *openapi3.Response, error) {
+	if operation.Responses == nil {
+		return "", nil, nil
+	}
+	if upper := strings.ToUpper(code); len(upper) == 3 && upper[0] >= '1' && upper[0] <= '5' && upper[1:] == "XX" {
+		for _, key := range sortedKeys(operation.Responses.Map()) {
+			if len(key) == 3 && key[0] == upper[0] {
+				if responseRef := operation.Responses.Value(key); responseRef != nil && responseRef.Value != nil {
+					return key, responseRef.Value, nil
+				}
+			}
+		}
+		if responseRef := operation.Responses.Default(); responseRef != nil && responseRef.Value != nil {
+			return upper, responseRef.Value, nil
+		}
+		return "", nil, nil
+	}
+
+	status, err := strconv.Atoi(code)
+	if err != nil || status < 100 || status > 599 {
+		return "", nil, fmt.Errorf("invalid Prefer code: %s, expected a status code such as 404 or a range such as 4XX", code)
+	}
+	if _, response := matchResponse(operation, status); response != nil {
+		return code, response, nil
+	}
+	return "", nil, nil
+}
+
+// acceptedMediaType picks the documented media type the Accept header asks for, defaulting the
+// same way generate_example does
+func acceptedMediaType(content openapi3.Content, accept string) (string, *openapi3.MediaType, error) {
+	for _, entry := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(entry, ";")
+		if media := content.Get(strings.TrimSpace(mediaType)); media != nil && !strings.Contains(mediaType, "*") {
+			return strings.TrimSpace(mediaType), media, nil
+		}
+	}
+	return selectMediaType(content, "")
+}
+
+// writeMockError sends an error the way the mock reports every problem, as a JSON object
+func writeMockError(w http.ResponseWriter, status int, message string, violations []violation) {
+	body := map[string]interface{}{"error": message}
+	if len(violations) > 0 {
+		body["violations"] = violations
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// ServeHTTP answers requests to the spec's operations with their documented examples, or data
+// synthesized from the schemas, after checking the request against the operation
+func (oas *OpenAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Browsers prototyping against the mock need CORS
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Expose-Headers", "*")
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(methodOrder, ", "))
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	oas.mu.RLock()
+	defer oas.mu.RUnlock()
+
+	// HEAD is answered like GET, without the body, unless the spec documents it
+	_, route, pathParams, err := oas.matchURL(r.Method, r.URL)
+	if err != nil && r.Method == http.MethodHead {
+		_, route, pathParams, err = oas.matchURL(http.MethodGet, r.URL)
+	}
+	if err != nil {
+		writeMockError(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	if violations := oas.validateRequest(r.Context(), route.Path, route.PathItem, route.Operation, r, pathParams, requestInput{}); len(violations) > 0 {
+		status := http.StatusUnauthorized
+		for _, v := range violations {
+			if v.In != "security" {
+				status = http.StatusBadRequest
+			}
+		}
+		writeMockError(w, status, fmt.Sprintf("request does not match %s %s", r.Method, route.Path), violations)
+		return
+	}
+
+	preferences := parsePrefer(r.Header.Values("Prefer"))
+	key, response, err := selectResponse(route.Operation, "")
+	if preferences.Code != "" {
+		key, response, err = preferredResponse(route.Operation, preferences.Code)
+		if err != nil {
+			writeMockError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		if response == nil {
+			writeMockError(w, http.StatusBadRequest, fmt.Sprintf("%s %s does not document status %s. Documented: %s", r.Method, route.Path, preferences.Code, strings.Join(sortedKeys(route.Operation.Responses.Map()), ", ")), nil)
+			return
+		}
+	} else if err != nil {
+		writeMockError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	generator := oas.newExampleGenerator(0, false)
+	for _, name := range sortedKeys(response.Headers) {
+		header := response.Headers[name]
+		if strings.EqualFold(name, "Content-Type") || header == nil || header.Value == nil {
+			continue
+		}
+		value := header.Value.Example
+		if value == nil {
+			value, _ = generator.generate(header.Value.Schema, 0)
+		}
+		w.Header().Set(name, primitiveString(value))
+	}
+
+	status := responseStatus(key)
+	if len(response.Content) == 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	mediaType, media, err := acceptedMediaType(response.Content, r.Header.Get("Accept"))
+	if err != nil {
+		writeMockError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	var example interface{}
+	if preferences.Example != "" {
+		named := media.Examples[preferences.Example]
+		if named == nil || named.Value == nil {
+			writeMockError(w, http.StatusBadRequest, fmt.Sprintf("example not found: %s. Available: %s", preferences.Example, strings.Join(sortedKeys(media.Examples), ", ")), nil)
+			return
+		}
+		example = named.Value.Value
+	} else {
+		example, _ = mediaTypeExample(media, generator)
+	}
+
+	var body []byte
+	if text, isText := example.(string); isText && !isJSONMediaType(mediaType) {
+		body = []byte(text)
+	} else if body, err = json.Marshal(example); err != nil {
+		writeMockError(w, http.StatusInternalServerError, fmt.Sprintf("failed to encode example: %v", err), nil)
+		return
+	}
+
+	// Generated values that break the schema are flagged the way HTTP flags a changed response
+	for _, warning := range generator.warnings {
+		w.Header().Add("Warning", fmt.Sprintf("199 - %q", warning))
+	}
+
+	// HEAD gets the same headers, the server leaves out the body
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// mockHandler routes mock requests to the specs in the registry. A single spec is served at the
+// root, several specs each under their name, e.g. /users/...
+func mockHandler(registry *SpecRegistry) http.Handler {
+	servers := registry.Servers()
+	mux := http.NewServeMux()
+	if len(servers) == 1 {
+		mux.Handle("/", servers[0])
+	} else {
+		for _, oas := range servers {
+			prefix := "/" + oas.name
+			mux.Handle(prefix+"/", http.StripPrefix(prefix, oas))
+		}
+	}
+	return mux
+}
+
+// StartMockServer serves mock responses for every spec in the registry, see mockHandler
+func StartMockServer(registry *SpecRegistry, addr string) error {
+	mux := mockHandler(registry)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		mux.ServeHTTP(recorder, r)
+		log.Printf("%s %s -> %d", r.Method, r.URL.RequestURI(), recorder.status)
+	})
+
+	log.Printf("Mock server starting on %s", addr)
+
+	return http.ListenAndServe(addr, handler)
+}
+
+// statusRecorder remembers the status code written for request logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
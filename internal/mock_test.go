@@ -0,0 +1,300 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const mockSpec = `
+openapi: 3.0.3
+info: {title: mock, version: "1"}
+servers: [{url: "https://api.example.com/v1"}]
+paths:
+  /pets/{id}:
+    get:
+      security: [{tokenAuth: []}]
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+      responses:
+        "200":
+          description: ok
+          headers:
+            X-Rate-Limit: {schema: {type: integer}, example: 100}
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Pet"}
+              examples:
+                rex: {value: {id: 1, name: Rex}}
+                tom: {value: {id: 2, name: Tom}}
+            application/xml:
+              example: "<pet><name>Rex</name></pet>"
+        "404":
+          description: missing
+          content:
+            application/json:
+              example: {error: not found}
+        "5XX":
+          description: server error
+          content:
+            application/json:
+              example: {error: unavailable}
+components:
+  securitySchemes:
+    tokenAuth: {type: http, scheme: bearer}
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id: {type: integer}
+        name: {type: string}
+`
+
+// mockRequest sends a request to the mock handler and returns the response with its body read
+func mockRequest(t *testing.T, handler http.Handler, method, target string, header map[string]string) (*http.Response, string) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	req, err := http.NewRequest(method, server.URL+target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+var authorized = map[string]string{"Authorization": "Bearer test"}
+
+// withHeader returns the authorized headers with one more header
+func withHeader(name, value string) map[string]string {
+	return map[string]string{"Authorization": "Bearer test", name: value}
+}
+
+func TestMockValidation(t *testing.T) {
+	oas := loadTestSpec(t, mockSpec)
+
+	tests := []struct {
+		name   string
+		target string
+		header map[string]string
+		status int
+		in     string
+	}{
+		{"valid", "/v1/pets/1", authorized, http.StatusOK, ""},
+		{"missing credentials", "/v1/pets/1", nil, http.StatusUnauthorized, "security"},
+		{"invalid parameter", "/v1/pets/abc", authorized, http.StatusBadRequest, "path"},
+		{"invalid parameter and missing credentials", "/v1/pets/abc", nil, http.StatusBadRequest, "path"},
+		{"unknown path", "/v1/owners", authorized, http.StatusNotFound, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, body := mockRequest(t, oas, http.MethodGet, test.target, test.header)
+			if resp.StatusCode != test.status {
+				t.Fatalf("status %d, want %d: %s", resp.StatusCode, test.status, body)
+			}
+			if test.in == "" {
+				return
+			}
+			var failure struct {
+				Violations []violation `json:"violations"`
+			}
+			if err := json.Unmarshal([]byte(body), &failure); err != nil {
+				t.Fatal(err)
+			}
+			found := false
+			for _, v := range failure.Violations {
+				found = found || v.In == test.in
+			}
+			if !found {
+				t.Fatalf("no %s violation reported: %s", test.in, body)
+			}
+		})
+	}
+}
+
+func TestMockPrefer(t *testing.T) {
+	oas := loadTestSpec(t, mockSpec)
+
+	tests := []struct {
+		prefer string
+		status int
+		body   string
+	}{
+		{"", http.StatusOK, `{"id":1,"name":"Rex"}`},
+		{"example=tom", http.StatusOK, `{"id":2,"name":"Tom"}`},
+		{"code=200, example=tom", http.StatusOK, `{"id":2,"name":"Tom"}`},
+		{"code=404", http.StatusNotFound, `{"error":"not found"}`},
+		{"code=4XX", http.StatusNotFound, `{"error":"not found"}`},
+		{"code=4xx", http.StatusNotFound, `{"error":"not found"}`},
+		{"code=503", http.StatusServiceUnavailable, `{"error":"unavailable"}`},
+		{"code=5XX", http.StatusInternalServerError, `{"error":"unavailable"}`},
+	}
+	for _, test := range tests {
+		header := authorized
+		if test.prefer != "" {
+			header = withHeader("Prefer", test.prefer)
+		}
+		resp, body := mockRequest(t, oas, http.MethodGet, "/v1/pets/1", header)
+		if resp.StatusCode != test.status || body != test.body {
+			t.Errorf("Prefer %q gave %d %s, want %d %s", test.prefer, resp.StatusCode, body, test.status, test.body)
+		}
+	}
+
+	for _, prefer := range []string{"code=401", "code=3XX", "code=abc", "code=4XXX", "example=missing"} {
+		if resp, body := mockRequest(t, oas, http.MethodGet, "/v1/pets/1", withHeader("Prefer", prefer)); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Prefer %q gave %d %s, want 400", prefer, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestMockAccept(t *testing.T) {
+	oas := loadTestSpec(t, mockSpec)
+
+	tests := []struct {
+		accept    string
+		mediaType string
+		body      string
+	}{
+		{"", "application/json", `{"id":1,"name":"Rex"}`},
+		{"application/xml", "application/xml", "<pet><name>Rex</name></pet>"},
+		{"text/html, application/xml;q=0.9", "application/xml", "<pet><name>Rex</name></pet>"},
+		{"*/*", "application/json", `{"id":1,"name":"Rex"}`},
+	}
+	for _, test := range tests {
+		resp, body := mockRequest(t, oas, http.MethodGet, "/v1/pets/1", withHeader("Accept", test.accept))
+		if got := resp.Header.Get("Content-Type"); got != test.mediaType || body != test.body {
+			t.Errorf("Accept %q gave %s %s, want %s %s", test.accept, got, body, test.mediaType, test.body)
+		}
+		if got := resp.Header.Get("X-Rate-Limit"); got != "100" {
+			t.Errorf("Accept %q gave X-Rate-Limit %q, want 100", test.accept, got)
+		}
+	}
+}
+
+func TestMockHead(t *testing.T) {
+	oas := loadTestSpec(t, mockSpec)
+
+	get, getBody := mockRequest(t, oas, http.MethodGet, "/v1/pets/1", authorized)
+	head, headBody := mockRequest(t, oas, http.MethodHead, "/v1/pets/1", authorized)
+	if head.StatusCode != http.StatusOK || headBody != "" {
+		t.Fatalf("HEAD gave %d with body %q", head.StatusCode, headBody)
+	}
+	for _, name := range []string{"Content-Type", "Content-Length", "X-Rate-Limit"} {
+		if head.Header.Get(name) != get.Header.Get(name) {
+			t.Errorf("HEAD %s is %q, GET sends %q", name, head.Header.Get(name), get.Header.Get(name))
+		}
+	}
+	if get.Header.Get("Content-Length") != "21" || getBody != `{"id":1,"name":"Rex"}` {
+		t.Fatalf("GET gave Content-Length %s and body %s", get.Header.Get("Content-Length"), getBody)
+	}
+
+	if resp, body := mockRequest(t, oas, http.MethodHead, "/v1/pets/1", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("HEAD without credentials gave %d %s, want 401", resp.StatusCode, body)
+	}
+}
+
+func TestMockHandlerPrefixes(t *testing.T) {
+	dir := t.TempDir()
+	sources := []SpecSource{}
+	for _, name := range []string{"pets", "users"} {
+		path := filepath.Join(dir, name+".yaml")
+		document := strings.NewReplacer("/pets/{id}", "/"+name+"/{id}", "Rex", name).Replace(mockSpec)
+		if err := os.WriteFile(path, []byte(document), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, SpecSource{Name: name, URL: path})
+	}
+	registry := NewSpecRegistry(sources, t.TempDir(), DownloadConfig{})
+	if err := registry.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	handler := mockHandler(registry)
+
+	tests := []struct {
+		target string
+		status int
+		body   string
+	}{
+		{"/pets/v1/pets/1", http.StatusOK, `{"id":1,"name":"pets"}`},
+		{"/users/users/1", http.StatusOK, `{"id":1,"name":"users"}`},
+		{"/users/v1/pets/1", http.StatusNotFound, ""},
+		{"/v1/pets/1", http.StatusNotFound, ""},
+	}
+	for _, test := range tests {
+		resp, body := mockRequest(t, handler, http.MethodGet, test.target, authorized)
+		if resp.StatusCode != test.status || test.body != "" && body != test.body {
+			t.Errorf("%s gave %d %s, want %d %s", test.target, resp.StatusCode, body, test.status, test.body)
+		}
+	}
+
+	// A single spec is served at the root
+	single := NewSpecRegistry(sources[:1], t.TempDir(), DownloadConfig{})
+	if err := single.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if resp, body := mockRequest(t, mockHandler(single), http.MethodGet, "/v1/pets/1", authorized); resp.StatusCode != http.StatusOK {
+		t.Fatalf("single spec gave %d %s at the root", resp.StatusCode, body)
+	}
+}
+
+func TestMockConcurrentVariantRequests(t *testing.T) {
+	oas := loadTestSpec(t, variantSpec)
+	server := httptest.NewServer(oas)
+	defer server.Close()
+
+	// Every response fills in the discriminator of the same documented example, run with -race
+	const requests = 50
+	errs := make(chan error, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(server.URL + "/pets")
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer resp.Body.Close()
+			var body map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				errs <- err
+				return
+			}
+			if resp.StatusCode != http.StatusOK || body["petType"] == nil || body["owner"] != "alice" {
+				errs <- fmt.Errorf("status %d, body %v", resp.StatusCode, body)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for name, schema := range oas.spec.Components.Schemas {
+		if example := schema.Value.Example.(map[string]interface{}); example["petType"] != nil || example["owner"] != nil {
+			t.Errorf("%s example was changed to %v", name, example)
+		}
+	}
+}
@@ -0,0 +1,529 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/oasdiff/yaml"
+)
+
+// literalKeywords hold example or literal values that must be left exactly as written
+var literalKeywords = map[string]bool{
+	"example":  true,
+	"examples": true,
+	"default":  true,
+	"const":    true,
+	"enum":     true,
+	"value":    true,
+}
+
+// namedMapKeywords hold maps keyed by names chosen by the spec author rather than by keywords
+var namedMapKeywords = map[string]bool{
+	"paths":           true,
+	"webhooks":        true,
+	"responses":       true,
+	"parameters":      true,
+	"requestBodies":   true,
+	"headers":         true,
+	"content":         true,
+	"encoding":        true,
+	"callbacks":       true,
+	"links":           true,
+	"securitySchemes": true,
+	"pathItems":       true,
+}
+
+// Keywords holding subschemas, as a single schema, a list of schemas or a map of named schemas
+var (
+	subschemaKeywords = map[string]bool{
+		"items":                 true,
+		"additionalItems":       true,
+		"additionalProperties":  true,
+		"not":                   true,
+		"contains":              true,
+		"propertyNames":         true,
+		"if":                    true,
+		"then":                  true,
+		"else":                  true,
+		"unevaluatedItems":      true,
+		"unevaluatedProperties": true,
+		"contentSchema":         true,
+	}
+	subschemaListKeywords = map[string]bool{
+		"allOf":       true,
+		"oneOf":       true,
+		"anyOf":       true,
+		"prefixItems": true,
+	}
+	subschemaMapKeywords = map[string]bool{
+		"properties":        true,
+		"patternProperties": true,
+		"dependentSchemas":  true,
+		"$defs":             true,
+	}
+)
+
+// normalizeOpenAPI31 rewrites an OpenAPI 3.1 document into the 3.0 form the loader understands:
+// type arrays with "null" become nullable, numeric exclusive bounds become boolean ones, const
+// also becomes a single-value enum, boolean subschemas become objects and $defs are hoisted into
+// components so references to them resolve. Keywords without a 3.0 equivalent (const, examples,
+// prefixItems, $defs) stay on the schema as extensions for rendering. Webhooks are returned
+// separately since the 3.0 model has no place for them, see loadWebhooks.
+func normalizeOpenAPI31(data []byte) (document []byte, webhooks []byte, err error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+
+	defs := &defsCollector{hoisted: map[string]string{}, schemas: map[string]interface{}{}}
+	if components, ok := doc["components"].(map[string]interface{}); ok {
+		defs.existing, _ = components["schemas"].(map[string]interface{})
+	}
+	normalizeNode(doc, "", false, defs)
+
+	if len(defs.hoisted) > 0 {
+		components, ok := doc["components"].(map[string]interface{})
+		if !ok {
+			components = map[string]interface{}{}
+			doc["components"] = components
+		}
+		schemas, ok := components["schemas"].(map[string]interface{})
+		if !ok {
+			schemas = map[string]interface{}{}
+			components["schemas"] = schemas
+		}
+		for name, schema := range defs.schemas {
+			schemas[name] = schema
+		}
+		rewriteRefs(doc, defs.hoisted)
+	}
+
+	if hooks, ok := doc["webhooks"]; ok {
+		if webhooks, err = json.Marshal(hooks); err != nil {
+			return nil, nil, err
+		}
+		delete(doc, "webhooks")
+	}
+
+	if document, err = json.Marshal(doc); err != nil {
+		return nil, nil, err
+	}
+	return document, webhooks, nil
+}
+
+// loadWebhooks decodes the webhooks split off by normalizeOpenAPI31 and resolves their refs in a
+// second pass of the loader that read the spec. The pass runs on a document holding only the
+// webhooks and sharing the components of the spec, so spec.Paths only ever holds real paths.
+func loadWebhooks(loader *openapi3.Loader, spec *openapi3.T, data []byte, location *url.URL) (map[string]*openapi3.PathItem, error) {
+	webhooks := map[string]*openapi3.PathItem{}
+	if len(data) == 0 {
+		return webhooks, nil
+	}
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return nil, fmt.Errorf("invalid webhooks: %w", err)
+	}
+
+	doc := &openapi3.T{
+		OpenAPI:    spec.OpenAPI,
+		Info:       spec.Info,
+		Components: spec.Components,
+		Paths:      openapi3.NewPaths(),
+	}
+	for name, pathItem := range webhooks {
+		doc.Paths.Set(name, pathItem)
+	}
+	if err := loader.ResolveRefsIn(doc, location); err != nil {
+		return nil, fmt.Errorf("failed to resolve webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// normalizeNode walks the document down to the places that hold schemas: the schema of
+// parameters, headers and media types, the schemas of components and top-level $defs. Children of named maps are
+// always walked; elsewhere literal values and extensions are skipped.
+func normalizeNode(node interface{}, pointer string, named bool, defs *defsCollector) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			childPointer := pointer + "/" + escapePointer(key)
+			switch {
+			case named:
+				normalizeNode(child, childPointer, false, defs)
+			case literalKeywords[key] || strings.HasPrefix(key, "x-"):
+			case key == "schema":
+				value[key] = normalizeSchemaNode(child, childPointer, defs)
+			case key == "schemas" || key == "$defs":
+				// Component schemas, or definitions kept at the top of the document as in JSON Schema
+				if schemas, ok := child.(map[string]interface{}); ok {
+					for name, schema := range schemas {
+						schemas[name] = normalizeSchemaNode(schema, childPointer+"/"+escapePointer(name), defs)
+					}
+				}
+			default:
+				normalizeNode(child, childPointer, namedMapKeywords[key], defs)
+			}
+		}
+		if !named {
+			defs.hoist(value, pointer)
+		}
+	case []interface{}:
+		for i, child := range value {
+			normalizeNode(child, pointer+"/"+strconv.Itoa(i), false, defs)
+		}
+	}
+}
+
+// normalizeSchemaNode rewrites a schema and every subschema below it, returning the schema to use
+// in its place. Only keywords that hold subschemas are followed, so examples, defaults and const
+// values are left as written.
+func normalizeSchemaNode(node interface{}, pointer string, defs *defsCollector) interface{} {
+	node = booleanSchema(node)
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return node
+	}
+	normalizeSchema(schema)
+
+	for key, child := range schema {
+		childPointer := pointer + "/" + escapePointer(key)
+		switch {
+		case subschemaKeywords[key]:
+			if _, isBool := child.(bool); !isBool {
+				schema[key] = normalizeSchemaNode(child, childPointer, defs)
+			}
+		case subschemaListKeywords[key]:
+			if members, ok := child.([]interface{}); ok {
+				for i, member := range members {
+					members[i] = normalizeSchemaNode(member, childPointer+"/"+strconv.Itoa(i), defs)
+				}
+			}
+		case subschemaMapKeywords[key]:
+			if members, ok := child.(map[string]interface{}); ok {
+				for name, member := range members {
+					members[name] = normalizeSchemaNode(member, childPointer+"/"+escapePointer(name), defs)
+				}
+			}
+		}
+	}
+
+	defs.hoist(schema, pointer)
+	return schema
+}
+
+// normalizeSchema rewrites the 3.1 keywords of a single schema object
+func normalizeSchema(schema map[string]interface{}) {
+	// Nullability is expressed by including "null" in the type
+	switch types := schema["type"].(type) {
+	case []interface{}:
+		remaining := []interface{}{}
+		for _, t := range types {
+			if t == "null" {
+				schema["nullable"] = true
+			} else {
+				remaining = append(remaining, t)
+			}
+		}
+		switch len(remaining) {
+		case 0:
+			delete(schema, "type")
+		case 1:
+			schema["type"] = remaining[0]
+		default:
+			schema["type"] = remaining
+		}
+	case string:
+		if types == "null" {
+			schema["nullable"] = true
+			delete(schema, "type")
+		}
+	}
+
+	// Exclusive bounds are numbers instead of flags on minimum/maximum
+	for _, bound := range []struct{ exclusive, inclusive string }{
+		{"exclusiveMinimum", "minimum"},
+		{"exclusiveMaximum", "maximum"},
+	} {
+		limit, ok := schema[bound.exclusive].(float64)
+		if !ok {
+			continue
+		}
+		schema[bound.exclusive] = true
+		if current, ok := schema[bound.inclusive].(float64); ok {
+			// Keep whichever bound is stricter
+			if (bound.inclusive == "minimum" && current > limit) || (bound.inclusive == "maximum" && current < limit) {
+				delete(schema, bound.exclusive)
+				continue
+			}
+		}
+		schema[bound.inclusive] = limit
+	}
+
+	if constValue, exists := schema["const"]; exists {
+		if _, hasEnum := schema["enum"]; !hasEnum {
+			schema["enum"] = []interface{}{constValue}
+		}
+	}
+
+	// Boolean subschemas: true accepts anything, false accepts nothing
+	for _, keyword := range []string{"properties", "patternProperties", "dependentSchemas", "$defs"} {
+		if members, ok := schema[keyword].(map[string]interface{}); ok {
+			for name, member := range members {
+				members[name] = booleanSchema(member)
+			}
+		}
+	}
+	for _, keyword := range []string{"allOf", "oneOf", "anyOf", "prefixItems"} {
+		if members, ok := schema[keyword].([]interface{}); ok {
+			for i, member := range members {
+				members[i] = booleanSchema(member)
+			}
+		}
+	}
+	for _, keyword := range []string{"not", "contains"} {
+		if member, exists := schema[keyword]; exists {
+			schema[keyword] = booleanSchema(member)
+		}
+	}
+	// The 3.0 model has no tuples and requires items on arrays, so items: false after prefixItems
+	// becomes a maxItems bound and the items themselves accept anything
+	prefixItems, isTuple := schema["prefixItems"].([]interface{})
+	if items, ok := schema["items"].(bool); ok {
+		schema["items"] = map[string]interface{}{}
+		if !items {
+			if _, exists := schema["maxItems"]; !exists {
+				schema["maxItems"] = len(prefixItems)
+			}
+		}
+	} else if _, exists := schema["items"]; !exists && isTuple {
+		schema["items"] = map[string]interface{}{}
+	}
+}
+
+func booleanSchema(value interface{}) interface{} {
+	accept, ok := value.(bool)
+	if !ok {
+		return value
+	}
+	if accept {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"not": map[string]interface{}{}}
+}
+
+// defsCollector gathers $defs to hoist into components/schemas once the walk is done, remembering
+// the old and new pointers
+type defsCollector struct {
+	existing map[string]interface{} // components/schemas of the document
+	schemas  map[string]interface{} // new component name -> definition
+	hoisted  map[string]string      // old pointer -> new pointer
+}
+
+// hoist takes the $defs of one schema. Each definition is replaced by a reference to its new
+// location so the schema still lists what it defines.
+func (dc *defsCollector) hoist(schema map[string]interface{}, pointer string) {
+	defs, ok := schema["$defs"].(map[string]interface{})
+	if !ok || len(defs) == 0 {
+		return
+	}
+
+	// Definitions under a component are prefixed with its name, e.g. Pet.Point
+	owner := ""
+	if rest, found := strings.CutPrefix(pointer, "/components/schemas/"); found {
+		segments := []string{}
+		for _, segment := range strings.Split(rest, "/") {
+			if segment != "$defs" {
+				segments = append(segments, unescapePointer(segment))
+			}
+		}
+		owner = strings.Join(segments, ".") + "."
+	}
+
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		newName := owner + name
+		for i := 2; dc.existing[newName] != nil || dc.schemas[newName] != nil; i++ {
+			newName = fmt.Sprintf("%s%s_%d", owner, name, i)
+		}
+		dc.schemas[newName] = defs[name]
+
+		newPointer := "/components/schemas/" + escapePointer(newName)
+		dc.hoisted[pointer+"/$defs/"+escapePointer(name)] = newPointer
+		defs[name] = map[string]interface{}{"$ref": "#" + newPointer}
+	}
+}
+
+// rewriteRefs points local references into hoisted $defs at their new location
+func rewriteRefs(node interface{}, hoisted map[string]string) {
+	// Longest pointers first so nested definitions win over their parents
+	oldPointers := make([]string, 0, len(hoisted))
+	for oldPointer := range hoisted {
+		oldPointers = append(oldPointers, oldPointer)
+	}
+	sort.Slice(oldPointers, func(i, j int) bool {
+		return len(oldPointers[i]) > len(oldPointers[j])
+	})
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok && strings.HasPrefix(ref, "#/") {
+				pointer := strings.TrimPrefix(ref, "#")
+				for _, oldPointer := range oldPointers {
+					if pointer == oldPointer || strings.HasPrefix(pointer, oldPointer+"/") {
+						value["$ref"] = "#" + hoisted[oldPointer] + strings.TrimPrefix(pointer, oldPointer)
+						break
+					}
+				}
+			}
+			for key, child := range value {
+				if key == "example" || key == "examples" || key == "default" || key == "const" || key == "enum" {
+					continue
+				}
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				walk(child)
+			}
+		}
+	}
+	walk(node)
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// addJSONSchemaKeywords renders the OpenAPI 3.1 keywords that the 3.0 model keeps as extensions
+func (oas *OpenAPIServer) addJSONSchemaKeywords(result map[string]interface{}, schema *openapi3.Schema, currentDepth, maxDepth int) {
+	if constValue, exists := schema.Extensions["const"]; exists {
+		result["const"] = constValue
+	}
+	if examples, ok := schema.Extensions["examples"].([]interface{}); ok {
+		result["examples"] = examples
+	}
+	for _, keyword := range []string{"contentMediaType", "contentEncoding"} {
+		if value, ok := schema.Extensions[keyword].(string); ok {
+			result[keyword] = value
+		}
+	}
+
+	if prefixItems, ok := schema.Extensions["prefixItems"].([]interface{}); ok && len(prefixItems) > 0 {
+		if currentDepth < maxDepth {
+			rendered := make([]map[string]interface{}, 0, len(prefixItems))
+			for _, item := range prefixItems {
+				rendered = append(rendered, oas.schemaToMapWithDepth(rawSchemaRef(item), currentDepth+1, maxDepth))
+			}
+			result["prefixItems"] = rendered
+		} else {
+			result["prefixItems"] = fmt.Sprintf("[%d schemas not expanded]", len(prefixItems))
+		}
+	}
+
+	if defs, ok := schema.Extensions["$defs"].(map[string]interface{}); ok && len(defs) > 0 {
+		// Definitions were hoisted into components, so these are references to them
+		rendered := map[string]interface{}{}
+		for name, def := range defs {
+			rendered[name] = oas.schemaToMapWithDepth(rawSchemaRef(def), currentDepth+1, maxDepth)
+		}
+		result["$defs"] = rendered
+	}
+}
+
+// rawSchemaRef decodes a schema kept as an extension value. References are left unresolved and
+// rendered as such.
+func rawSchemaRef(value interface{}) *openapi3.SchemaRef {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	schemaRef := &openapi3.SchemaRef{}
+	if err := schemaRef.UnmarshalJSON(data); err != nil {
+		return nil
+	}
+	return schemaRef
+}
+
+func (oas *OpenAPIServer) listWebhooksHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if len(oas.webhooks) == 0 {
+		return mcp.NewToolResultText("No webhooks found in the OpenAPI specification"), nil
+	}
+
+	// Ordered by name, then by method like list_endpoints
+	webhooks := []map[string]interface{}{}
+	for _, name := range sortedKeys(oas.webhooks) {
+		pathItem := oas.webhooks[name]
+		for _, method := range methodOrder {
+			operation := pathItem.GetOperation(method)
+			if operation == nil {
+				continue
+			}
+			webhook := map[string]interface{}{
+				"name":    name,
+				"method":  method,
+				"summary": operation.Summary,
+			}
+			if operation.Description != "" {
+				webhook["description"] = operation.Description
+			}
+			if operation.OperationID != "" {
+				webhook["operationId"] = operation.OperationID
+			}
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	return JSONResponse(webhooks)
+}
+
+func (oas *OpenAPIServer) showWebhookHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pathItem, exists := oas.webhooks[name]
+	if !exists {
+		return mcp.NewToolResultError(fmt.Sprintf("Webhook not found: %s", name)), nil
+	}
+
+	// The method may be omitted for the usual single-operation webhook
+	method := strings.ToUpper(request.GetString("method", ""))
+	if method == "" {
+		operations := pathItem.Operations()
+		if len(operations) != 1 {
+			return mcp.NewToolResultError(fmt.Sprintf("Webhook %s has %d operations, specify a method", name, len(operations))), nil
+		}
+		for only := range operations {
+			method = only
+		}
+	}
+
+	operation := pathItem.GetOperation(method)
+	if operation == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Method %s not found for webhook: %s", method, name)), nil
+	}
+
+	result := oas.operationToMap(pathItem, operation, request.GetBool("merged", false))
+	result["name"] = name
+	result["method"] = method
+
+	return JSONResponse(result)
+}
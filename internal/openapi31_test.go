@@ -0,0 +1,196 @@
+package internal
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeOpenAPI31LeavesLiteralsAlone(t *testing.T) {
+	document := `
+openapi: 3.1.0
+info: {title: literals, version: "1", x-shape: {type: [string, "null"]}}
+paths:
+  /things:
+    get:
+      parameters:
+        - name: filter
+          in: query
+          schema:
+            type: object
+            properties:
+              name: {type: [string, "null"]}
+          example: {type: [string, "null"], exclusiveMinimum: 1}
+      responses:
+        "200":
+          description: ok
+          links:
+            next:
+              operationId: getThing
+              parameters:
+                filter: {type: [string, "null"]}
+              requestBody: {type: [string, "null"], exclusiveMinimum: 1}
+          headers:
+            X-Count:
+              schema: {type: [integer, "null"], exclusiveMinimum: 0}
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  shape:
+                    type: object
+                    const: {type: [string, "null"]}
+                    default: {examples: [1], type: [number, "null"]}
+              examples:
+                sample:
+                  value: {type: [string, "null"]}
+components:
+  examples:
+    Shape:
+      value: {type: [string, "null"], items: false}
+`
+	data, _, err := normalizeOpenAPI31([]byte(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	literal := map[string]interface{}{"type": []interface{}{"string", "null"}}
+	get := lookup(t, doc, "paths", "/things", "get").(map[string]interface{})
+	response := lookup(t, get, "responses", "200").(map[string]interface{})
+	shape := lookup(t, response, "content", "application/json", "schema", "properties", "shape").(map[string]interface{})
+
+	unchanged := map[string]struct{ got, want interface{} }{
+		"info extension": {lookup(t, doc, "info", "x-shape"), literal},
+		"const":          {shape["const"], literal},
+		"default":        {shape["default"], map[string]interface{}{"examples": []interface{}{1.0}, "type": []interface{}{"number", "null"}}},
+		"link parameter": {lookup(t, response, "links", "next", "parameters", "filter"), literal},
+		"link body": {lookup(t, response, "links", "next", "requestBody"),
+			map[string]interface{}{"type": []interface{}{"string", "null"}, "exclusiveMinimum": 1.0}},
+		"media example": {lookup(t, response, "content", "application/json", "examples", "sample", "value"), literal},
+		"component example": {lookup(t, doc, "components", "examples", "Shape", "value"),
+			map[string]interface{}{"type": []interface{}{"string", "null"}, "items": false}},
+		"parameter example": {lookup(t, get, "parameters", 0, "example"),
+			map[string]interface{}{"type": []interface{}{"string", "null"}, "exclusiveMinimum": 1.0}},
+	}
+	for name, check := range unchanged {
+		if !reflect.DeepEqual(check.got, check.want) {
+			t.Errorf("%s was rewritten: got %v, want %v", name, check.got, check.want)
+		}
+	}
+
+	// Schemas in the same places are normalized
+	name := lookup(t, get, "parameters", 0, "schema", "properties", "name").(map[string]interface{})
+	if name["type"] != "string" || name["nullable"] != true {
+		t.Errorf("parameter property not normalized: %v", name)
+	}
+	count := lookup(t, response, "headers", "X-Count", "schema").(map[string]interface{})
+	if count["type"] != "integer" || count["nullable"] != true || count["minimum"] != 0.0 || count["exclusiveMinimum"] != true {
+		t.Errorf("header schema not normalized: %v", count)
+	}
+}
+
+// lookup follows map keys and list indexes into a decoded document
+func lookup(t *testing.T, node interface{}, path ...interface{}) interface{} {
+	t.Helper()
+	for _, step := range path {
+		switch key := step.(type) {
+		case string:
+			object, ok := node.(map[string]interface{})
+			if !ok {
+				t.Fatalf("expected an object at %v", step)
+			}
+			node = object[key]
+		case int:
+			list, ok := node.([]interface{})
+			if !ok || key >= len(list) {
+				t.Fatalf("expected a list at %v", step)
+			}
+			node = list[key]
+		}
+	}
+	return node
+}
+
+func TestListWebhooksOrder(t *testing.T) {
+	document := "openapi: 3.1.0\ninfo: {title: hooks, version: \"1\"}\npaths: {}\nwebhooks:\n"
+	want := []string{}
+	for _, name := range []string{"shipped", "created", "deleted", "archived"} {
+		document += "  " + name + ":\n"
+		for _, method := range []string{"put", "get", "delete", "post"} {
+			document += "    " + method + ": {responses: {\"200\": {description: ok}}}\n"
+		}
+	}
+	for _, name := range []string{"archived", "created", "deleted", "shipped"} {
+		for _, method := range []string{"GET", "POST", "PUT", "DELETE"} {
+			want = append(want, name+" "+method)
+		}
+	}
+	oas := loadTestSpec(t, document)
+
+	for i := 0; i < 10; i++ {
+		var webhooks []struct {
+			Name   string `json:"name"`
+			Method string `json:"method"`
+		}
+		callToolJSON(t, oas.listWebhooksHandler, nil, &webhooks)
+		got := []string{}
+		for _, webhook := range webhooks {
+			got = append(got, webhook.Name+" "+webhook.Method)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d: got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestWebhookRefsResolve(t *testing.T) {
+	oas := loadTestSpec(t, `
+openapi: 3.1.0
+info: {title: hooks, version: "1"}
+paths:
+  /pets:
+    get: {responses: {"200": {description: ok}}}
+webhooks:
+  newPet:
+    post:
+      requestBody: {$ref: "#/components/requestBodies/Pet"}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Ack"}
+components:
+  requestBodies:
+    Pet:
+      content:
+        application/json:
+          schema: {$ref: "#/components/schemas/Pet"}
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name: {type: [string, "null"]}
+    Ack: {type: boolean}
+`)
+	if keys := oas.spec.Paths.InMatchingOrder(); !reflect.DeepEqual(keys, []string{"/pets"}) {
+		t.Fatalf("paths hold %v, want only /pets", keys)
+	}
+
+	operation := oas.webhooks["newPet"].Post
+	if operation == nil {
+		t.Fatal("webhook newPet has no POST operation")
+	}
+	pet := operation.RequestBody.Value.Content.Get("application/json").Schema.Value
+	if pet == nil || pet.Properties["name"] == nil || !pet.Properties["name"].Value.Nullable {
+		t.Fatalf("request body schema was not resolved: %+v", pet)
+	}
+	if ack := operation.Responses.Status(200).Value.Content.Get("application/json").Schema.Value; ack == nil || !ack.Type.Is("boolean") {
+		t.Fatalf("response schema was not resolved: %+v", ack)
+	}
+}
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// declaredParameter is a parameter together with the level it was declared at
+type declaredParameter struct {
+	Ref        *openapi3.ParameterRef
+	DeclaredAt string // "path" or "operation"
+}
+
+// effectiveParameters merges path-level and operation-level parameters. An operation parameter
+// overrides a path parameter with the same name and location, as defined by the OpenAPI spec.
+func effectiveParameters(pathItem *openapi3.PathItem, operation *openapi3.Operation) []declaredParameter {
+	params := []declaredParameter{}
+	overridden := map[string]bool{}
+
+	for _, paramRef := range operation.Parameters {
+		if paramRef != nil && paramRef.Value != nil {
+			overridden[paramRef.Value.In+":"+paramRef.Value.Name] = true
+		}
+	}
+
+	for _, paramRef := range pathItem.Parameters {
+		if paramRef == nil || paramRef.Value == nil || overridden[paramRef.Value.In+":"+paramRef.Value.Name] {
+			continue
+		}
+		params = append(params, declaredParameter{Ref: paramRef, DeclaredAt: "path"})
+	}
+
+	for _, paramRef := range operation.Parameters {
+		if paramRef == nil || paramRef.Value == nil {
+			continue
+		}
+		params = append(params, declaredParameter{Ref: paramRef, DeclaredAt: "operation"})
+	}
+
+	return params
+}
+
+// methodOrder is the canonical order in which operations on the same path are listed
+var methodOrder = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodTrace,
+	http.MethodConnect,
+}
+
+// operationEntry is an operation together with the path it is declared on
+type operationEntry struct {
+	Path      string
+	Method    string
+	PathItem  *openapi3.PathItem
+	Operation *openapi3.Operation
+}
+
+// sortedOperations returns every operation in the spec ordered by path, then by canonical method order
+func sortedOperations(spec *openapi3.T) []operationEntry {
+	entries := []operationEntry{}
+	if spec.Paths == nil {
+		return entries
+	}
+
+	paths := make([]string, 0, spec.Paths.Len())
+	for path := range spec.Paths.Map() {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem := spec.Paths.Value(path)
+		for _, method := range methodOrder {
+			if operation := pathItem.GetOperation(method); operation != nil {
+				entries = append(entries, operationEntry{
+					Path:      path,
+					Method:    method,
+					PathItem:  pathItem,
+					Operation: operation,
+				})
+			}
+		}
+	}
+
+	return entries
+}
+
+// findOperation looks up the operation for a path template and method
+func (oas *OpenAPIServer) findOperation(path, method string) (*openapi3.PathItem, *openapi3.Operation, error) {
+	pathItem := oas.spec.Paths.Find(path)
+	if pathItem == nil {
+		return nil, nil, fmt.Errorf("Path not found: %s", path)
+	}
+
+	operation := pathItem.GetOperation(strings.ToUpper(method))
+	if operation == nil {
+		return nil, nil, fmt.Errorf("Method %s not found for path: %s", method, path)
+	}
+
+	return pathItem, operation, nil
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShowEndpointParameterOverrides(t *testing.T) {
+	oas := loadTestSpec(t, `
+openapi: 3.0.3
+info: {title: parameters, version: "1"}
+paths:
+  /pets/{id}:
+    parameters:
+      - {name: id, in: path, required: true, description: path level id, schema: {type: string}}
+      - {name: verbose, in: query, description: inherited flag, schema: {type: boolean}}
+      - {name: limit, in: query, description: path level limit, schema: {type: integer}}
+    get:
+      parameters:
+        - {name: id, in: path, required: true, description: operation level id, schema: {type: integer}}
+        - {name: limit, in: header, description: same name in another location, schema: {type: integer}}
+      responses: {"200": {description: ok}}
+`)
+
+	var endpoint struct {
+		Parameters []struct {
+			Name        string `json:"name"`
+			In          string `json:"in"`
+			Description string `json:"description"`
+			DeclaredAt  string `json:"declaredAt"`
+		} `json:"parameters"`
+	}
+	callToolJSON(t, oas.showEndpointHandler, map[string]interface{}{"path": "/pets/{id}", "method": "get"}, &endpoint)
+
+	got := []string{}
+	for _, param := range endpoint.Parameters {
+		got = append(got, param.In+" "+param.Name+" from "+param.DeclaredAt+": "+param.Description)
+	}
+	// The operation's id replaces the path's, matched on name and location together
+	want := []string{
+		"query verbose from path: inherited flag",
+		"query limit from path: path level limit",
+		"path id from operation: operation level id",
+		"header limit from operation: same name in another location",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parameters:\n%v\nwant\n%v", got, want)
+	}
+}
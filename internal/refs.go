@@ -0,0 +1,462 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/jsonpointer"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/oasdiff/yaml"
+)
+
+// MaxRefChainLength bounds how many alias references are followed before giving up
+const MaxRefChainLength = 16
+
+func (oas *OpenAPIServer) resolveRefHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ref, err := request.RequireString("ref")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	value, chain, err := oas.resolveRef(ref)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := oas.refValueToMap(value)
+	result["ref"] = ref
+	if len(chain) > 1 {
+		result["chain"] = chain
+	}
+
+	return JSONResponse(result)
+}
+
+// resolveRef resolves a local JSON pointer (#/...) against the loaded spec, or an external
+// reference (file.yaml#/...) that the spec itself uses. Components that are aliases of other
+// references are followed, and the returned chain lists every reference visited.
+func (oas *OpenAPIServer) resolveRef(ref string) (interface{}, []string, error) {
+	chain := []string{}
+	var externalRefs map[string]interface{}
+	var location *url.URL
+
+	for len(chain) < MaxRefChainLength {
+		chain = append(chain, ref)
+
+		var value interface{}
+		if strings.HasPrefix(ref, "#") {
+			pointer, err := jsonpointer.New(strings.TrimPrefix(ref, "#"))
+			if err != nil {
+				return nil, chain, fmt.Errorf("invalid JSON pointer %s: %w", ref, err)
+			}
+			value, _, err = pointer.Get(oas.spec)
+			if err != nil {
+				return nil, chain, fmt.Errorf("reference not found: %s (%v)", ref, err)
+			}
+		} else {
+			// External documents are only reachable through the references the loader resolved
+			if externalRefs == nil {
+				location, _ = oas.specLocation()
+				externalRefs = collectRefs(oas.spec, location)
+			}
+			var exists bool
+			value, exists = externalRefs[normalizeRef(location, ref)]
+			if !exists {
+				value, exists = externalRefs[ref]
+			}
+			if !exists {
+				return nil, chain, fmt.Errorf("external reference not used by the loaded spec: %s", ref)
+			}
+		}
+
+		if alias, ok := value.(*openapi3.Ref); ok {
+			ref = alias.Ref
+			continue
+		}
+		if value == nil {
+			return nil, chain, fmt.Errorf("reference resolves to an empty value: %s", ref)
+		}
+		return value, chain, nil
+	}
+
+	return nil, chain, fmt.Errorf("reference chain too long: %s", strings.Join(chain, " -> "))
+}
+
+// refValueToMap renders a resolved reference according to the kind of object it points to
+func (oas *OpenAPIServer) refValueToMap(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case *openapi3.Schema:
+		return map[string]interface{}{
+			"kind":   "schema",
+			"schema": oas.schemaToMapWithDepth(&openapi3.SchemaRef{Value: v}, 0, DetailedSchemaMaxDepth),
+		}
+	case *openapi3.Parameter:
+		return map[string]interface{}{
+			"kind":      "parameter",
+			"parameter": oas.parameterToMap(declaredParameter{Ref: &openapi3.ParameterRef{Value: v}}),
+		}
+	case *openapi3.Header:
+		header := oas.parameterToMap(declaredParameter{Ref: &openapi3.ParameterRef{Value: &v.Parameter}})
+		delete(header, "name")
+		delete(header, "in")
+		return map[string]interface{}{
+			"kind":   "header",
+			"header": header,
+		}
+	case *openapi3.RequestBody:
+		return map[string]interface{}{
+			"kind":        "requestBody",
+			"requestBody": oas.requestBodyToMap(v, false),
+		}
+	case *openapi3.Response:
+		return map[string]interface{}{
+			"kind":     "response",
+			"response": oas.responseToMap(v, false),
+		}
+	case *openapi3.SecurityScheme:
+		return map[string]interface{}{
+			"kind":           "securityScheme",
+			"securityScheme": securitySchemeToMap(v, true),
+		}
+	case *openapi3.Example:
+		example := map[string]interface{}{
+			"value": v.Value,
+		}
+		if v.Summary != "" {
+			example["summary"] = v.Summary
+		}
+		if v.Description != "" {
+			example["description"] = v.Description
+		}
+		if v.ExternalValue != "" {
+			example["externalValue"] = v.ExternalValue
+		}
+		return map[string]interface{}{
+			"kind":    "example",
+			"example": example,
+		}
+	case *openapi3.PathItem:
+		operations := []map[string]interface{}{}
+		for _, method := range methodOrder {
+			operation := v.GetOperation(method)
+			if operation == nil {
+				continue
+			}
+			operations = append(operations, map[string]interface{}{
+				"method":      method,
+				"summary":     operation.Summary,
+				"operationId": operation.OperationID,
+			})
+		}
+		return map[string]interface{}{
+			"kind":       "pathItem",
+			"operations": operations,
+		}
+	case *openapi3.Operation:
+		return map[string]interface{}{
+			"kind":        "operation",
+			"summary":     v.Summary,
+			"description": v.Description,
+			"operationId": v.OperationID,
+			"tags":        v.Tags,
+		}
+	default:
+		return map[string]interface{}{
+			"kind":  "value",
+			"value": v,
+		}
+	}
+}
+
+// collectRefs maps every reference string used in the spec to the value the loader resolved it to.
+// Each reference is keyed both as written and by the absolute location it points to, see
+// normalizeRef.
+func collectRefs(spec *openapi3.T, location *url.URL) map[string]interface{} {
+	refs := map[string]interface{}{}
+	add := func(ref string, refPath *url.URL, value interface{}) {
+		refs[ref] = value
+		// The loader knows where refs in other documents point, the rest are in the root document
+		if refPath != nil {
+			refs[refPath.String()] = value
+		} else if normalized := normalizeRef(location, ref); normalized != "" {
+			refs[normalized] = value
+		}
+	}
+	visitedSchemas := map[*openapi3.Schema]bool{}
+
+	var visitSchema func(schemaRef *openapi3.SchemaRef)
+	visitSchema = func(schemaRef *openapi3.SchemaRef) {
+		if schemaRef == nil || schemaRef.Value == nil {
+			return
+		}
+		if schemaRef.Ref != "" {
+			add(schemaRef.Ref, schemaRef.RefPath(), schemaRef.Value)
+		}
+		if visitedSchemas[schemaRef.Value] {
+			return
+		}
+		visitedSchemas[schemaRef.Value] = true
+
+		schema := schemaRef.Value
+		for _, prop := range schema.Properties {
+			visitSchema(prop)
+		}
+		for _, members := range []openapi3.SchemaRefs{schema.AllOf, schema.OneOf, schema.AnyOf} {
+			for _, member := range members {
+				visitSchema(member)
+			}
+		}
+		visitSchema(schema.Items)
+		visitSchema(schema.Not)
+		visitSchema(schema.AdditionalProperties.Schema)
+	}
+
+	visitContent := func(content openapi3.Content) {
+		for _, mediaType := range content {
+			if mediaType == nil {
+				continue
+			}
+			visitSchema(mediaType.Schema)
+			for _, exampleRef := range mediaType.Examples {
+				if exampleRef != nil && exampleRef.Ref != "" {
+					add(exampleRef.Ref, exampleRef.RefPath(), exampleRef.Value)
+				}
+			}
+		}
+	}
+
+	visitParameter := func(paramRef *openapi3.ParameterRef) {
+		if paramRef == nil || paramRef.Value == nil {
+			return
+		}
+		if paramRef.Ref != "" {
+			add(paramRef.Ref, paramRef.RefPath(), paramRef.Value)
+		}
+		visitSchema(paramRef.Value.Schema)
+		visitContent(paramRef.Value.Content)
+	}
+
+	visitHeaders := func(headers openapi3.Headers) {
+		for _, headerRef := range headers {
+			if headerRef == nil || headerRef.Value == nil {
+				continue
+			}
+			if headerRef.Ref != "" {
+				add(headerRef.Ref, headerRef.RefPath(), headerRef.Value)
+			}
+			visitSchema(headerRef.Value.Schema)
+		}
+	}
+
+	visitRequestBody := func(bodyRef *openapi3.RequestBodyRef) {
+		if bodyRef == nil || bodyRef.Value == nil {
+			return
+		}
+		if bodyRef.Ref != "" {
+			add(bodyRef.Ref, bodyRef.RefPath(), bodyRef.Value)
+		}
+		visitContent(bodyRef.Value.Content)
+	}
+
+	visitResponse := func(responseRef *openapi3.ResponseRef) {
+		if responseRef == nil || responseRef.Value == nil {
+			return
+		}
+		if responseRef.Ref != "" {
+			add(responseRef.Ref, responseRef.RefPath(), responseRef.Value)
+		}
+		visitHeaders(responseRef.Value.Headers)
+		visitContent(responseRef.Value.Content)
+	}
+
+	if spec.Components != nil {
+		for _, schemaRef := range spec.Components.Schemas {
+			visitSchema(schemaRef)
+		}
+		for _, paramRef := range spec.Components.Parameters {
+			visitParameter(paramRef)
+		}
+		visitHeaders(spec.Components.Headers)
+		for _, bodyRef := range spec.Components.RequestBodies {
+			visitRequestBody(bodyRef)
+		}
+		for _, responseRef := range spec.Components.Responses {
+			visitResponse(responseRef)
+		}
+		for _, schemeRef := range spec.Components.SecuritySchemes {
+			if schemeRef != nil && schemeRef.Ref != "" {
+				add(schemeRef.Ref, schemeRef.RefPath(), schemeRef.Value)
+			}
+		}
+		for _, exampleRef := range spec.Components.Examples {
+			if exampleRef != nil && exampleRef.Ref != "" {
+				add(exampleRef.Ref, exampleRef.RefPath(), exampleRef.Value)
+			}
+		}
+	}
+
+	if spec.Paths != nil {
+		for _, pathItem := range spec.Paths.Map() {
+			if pathItem.Ref != "" {
+				add(pathItem.Ref, nil, pathItem)
+			}
+			for _, paramRef := range pathItem.Parameters {
+				visitParameter(paramRef)
+			}
+			for _, operation := range pathItem.Operations() {
+				for _, paramRef := range operation.Parameters {
+					visitParameter(paramRef)
+				}
+				visitRequestBody(operation.RequestBody)
+				if operation.Responses != nil {
+					for _, responseRef := range operation.Responses.Map() {
+						visitResponse(responseRef)
+					}
+				}
+			}
+		}
+	}
+
+	return refs
+}
+
+// normalizeRef resolves a reference against the spec location, so that differently written
+// references to the same document, such as ./common.yaml#/X and common.yaml#/X, compare equal
+func normalizeRef(location *url.URL, ref string) string {
+	target, err := url.Parse(ref)
+	if location == nil || err != nil {
+		return ""
+	}
+	return location.ResolveReference(target).String()
+}
+
+// refSite is an external $ref found in a document the spec was loaded from
+type refSite struct {
+	Document string // the document holding the $ref
+	Ref      string // the $ref as written
+	Target   string // the document it points to, resolved against Document
+}
+
+func (site refSite) String() string {
+	return fmt.Sprintf("$ref %q in %s", site.Ref, site.Document)
+}
+
+// refTrail records the external refs of every document read while loading a spec, so that a
+// failing ref can be traced back to the root document through the refs that led to it
+type refTrail struct {
+	root      string
+	sites     []refSite
+	documents map[string]interface{}
+	// failed is the document that could not be read, if any
+	failed string
+}
+
+// addDocument records the external refs in a document
+func (trail *refTrail) addDocument(location *url.URL, data []byte) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return
+	}
+	if trail.documents == nil {
+		trail.documents = map[string]interface{}{}
+	}
+	trail.documents[location.String()] = document
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch v := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok && !strings.HasPrefix(ref, "#") {
+				refPath, _, _ := strings.Cut(ref, "#")
+				if target, err := url.Parse(refPath); err == nil {
+					trail.sites = append(trail.sites, refSite{
+						Document: location.String(),
+						Ref:      ref,
+						Target:   location.ResolveReference(target).String(),
+					})
+				}
+			}
+			for _, key := range sortedKeys(v) {
+				walk(v[key])
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	walk(document)
+}
+
+// referencedByLoader matches the loader's "error resolving reference" messages
+var referencedByLoader = regexp.MustCompile(`reference "([^"]+)"`)
+
+// explain returns the chain of refs from the root document to the one behind a loading error,
+// e.g. `$ref "pets.yaml#/Pet" in /api/openapi.yaml -> $ref "owner.yaml#/Owner" in /api/pets.yaml`.
+// It is empty when the failing ref is not an external one.
+func (trail *refTrail) explain(err error) string {
+	failing := trail.failingSite(err)
+	if failing == nil {
+		return ""
+	}
+
+	chain := []string{failing.String()}
+	visited := map[string]bool{failing.Document: true}
+	for document := failing.Document; document != trail.root; {
+		var referrer *refSite
+		for i := range trail.sites {
+			if trail.sites[i].Target == document && !visited[trail.sites[i].Document] {
+				referrer = &trail.sites[i]
+				break
+			}
+		}
+		if referrer == nil {
+			break
+		}
+		chain = append([]string{referrer.String()}, chain...)
+		visited[referrer.Document] = true
+		document = referrer.Document
+	}
+	return strings.Join(chain, " -> ")
+}
+
+// failingSite finds the external ref a loading error comes from: the one pointing to a document
+// that could not be read, the one the error names, or else one whose pointer is missing from its
+// document, which the loader does not name
+func (trail *refTrail) failingSite(err error) *refSite {
+	if trail.failed != "" {
+		for i := range trail.sites {
+			if trail.sites[i].Target == trail.failed {
+				return &trail.sites[i]
+			}
+		}
+		return nil
+	}
+
+	if match := referencedByLoader.FindStringSubmatch(err.Error()); match != nil {
+		for i := range trail.sites {
+			if trail.sites[i].Ref == match[1] {
+				return &trail.sites[i]
+			}
+		}
+	}
+
+	for i := range trail.sites {
+		site := &trail.sites[i]
+		document, loaded := trail.documents[site.Target]
+		_, fragment, _ := strings.Cut(site.Ref, "#")
+		if !loaded || fragment == "" {
+			continue
+		}
+		if pointer, err := jsonpointer.New(fragment); err == nil {
+			if _, _, err := pointer.Get(document); err != nil {
+				return site
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExternalRefChain(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"openapi.yaml": `
+openapi: 3.0.3
+info: {title: refs, version: "1"}
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: 'schemas/pet.yaml#/Pet'}
+`,
+		"schemas/pet.yaml": "Pet:\n  type: object\n  properties:\n    owner: {$ref: 'owner.yaml#/Owner'}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root := filepath.Join(dir, "openapi.yaml")
+	want := `$ref "schemas/pet.yaml#/Pet" in ` + filepath.ToSlash(root) + ` -> $ref "owner.yaml#/Owner" in ` + filepath.ToSlash(filepath.Join(dir, "schemas", "pet.yaml"))
+
+	// A missing document and a pointer missing from a document are both traced to the ref
+	for _, owner := range []string{"", "Other: {type: string}\n"} {
+		if owner != "" {
+			if err := os.WriteFile(filepath.Join(dir, "schemas", "owner.yaml"), []byte(owner), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		err := NewOpenAPIServer(root, t.TempDir()).LoadSpec()
+		if err == nil {
+			t.Fatal("expected the unresolvable ref to fail loading")
+		}
+		if !strings.Contains(err.Error(), "ref chain: "+want) {
+			t.Errorf("error does not name the ref chain:\n%v\nwant %s", err, want)
+		}
+	}
+}
+
+func TestRemoteSpecCannotReadLocalFiles(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret.yaml")
+	if err := os.WriteFile(secret, []byte("Secret: {type: string, description: leaked}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ref := range []string{"file://" + filepath.ToSlash(secret), filepath.ToSlash(secret)} {
+		t.Run(ref, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`openapi: 3.0.3
+info: {title: refs, version: "1"}
+paths: {}
+components:
+  schemas:
+    Leak: {$ref: '` + ref + `#/Secret'}
+`))
+			}))
+			defer server.Close()
+
+			err := NewOpenAPIServer(server.URL+"/openapi.yaml", t.TempDir()).LoadSpec()
+			if err == nil || !strings.Contains(err.Error(), "not allowed in a spec loaded from a URL") {
+				t.Fatalf("error = %v, want the local file ref to be refused", err)
+			}
+		})
+	}
+}
+
+func TestRemoteSpecRelativeRefsResolveAgainstURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/openapi.yaml":
+			w.Write([]byte(`openapi: 3.0.3
+info: {title: refs, version: "1"}
+paths: {}
+components:
+  schemas:
+    Error: {$ref: '../schemas/common.yaml#/Error'}
+`))
+		case "/schemas/common.yaml":
+			w.Write([]byte("Error: {type: object, description: remote}\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oas := NewOpenAPIServer(server.URL+"/api/openapi.yaml", t.TempDir())
+	if err := oas.LoadSpec(); err != nil {
+		t.Fatal(err)
+	}
+	if got := oas.spec.Components.Schemas["Error"].Value.Description; got != "remote" {
+		t.Errorf("description = %q, want the one from the document next to the spec URL", got)
+	}
+}
+
+// externalRefFiles is a spec whose schemas live in other documents, referenced in different ways
+var externalRefFiles = map[string]string{
+	"openapi.yaml": `
+openapi: 3.0.3
+info: {title: refs, version: "1"}
+paths:
+  /pets:
+    post:
+      responses: {"201": {description: created}}
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: './schemas/pet.yaml#/Pet'}
+    delete:
+      responses: {"204": {description: gone}}
+  /owners:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: 'schemas/pet.yaml#/Pet'}
+`,
+	"schemas/pet.yaml":   "Pet:\n  type: object\n  properties:\n    owner: {$ref: 'owner.yaml#/Owner'}\n",
+	"schemas/owner.yaml": "Owner:\n  type: object\n  properties:\n    name: {type: string}\n",
+}
+
+func TestResolveExternalRef(t *testing.T) {
+	oas := loadTestSpecFiles(t, externalRefFiles)
+
+	// Refs written differently but pointing to the same place resolve alike, and refs inside other
+	// documents resolve both as written there and relative to the spec
+	for ref, property := range map[string]string{
+		"./schemas/pet.yaml#/Pet":     "owner",
+		"schemas/pet.yaml#/Pet":       "owner",
+		"schemas/./pet.yaml#/Pet":     "owner",
+		"owner.yaml#/Owner":           "name",
+		"schemas/owner.yaml#/Owner":   "name",
+		"./schemas/owner.yaml#/Owner": "name",
+	} {
+		var result struct {
+			Kind   string `json:"kind"`
+			Schema struct {
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"schema"`
+		}
+		callToolJSON(t, oas.resolveRefHandler, map[string]interface{}{"ref": ref}, &result)
+		if _, exists := result.Schema.Properties[property]; result.Kind != "schema" || !exists {
+			t.Errorf("%s resolved to %s with properties %v, want %s", ref, result.Kind, result.Schema.Properties, property)
+		}
+	}
+
+	text, isError := callTool(t, oas.resolveRefHandler, map[string]interface{}{"ref": "schemas/other.yaml#/Pet"})
+	if !isError || !strings.Contains(text, "external reference not used by the loaded spec") {
+		t.Fatalf("ref unknown to the spec: %s", text)
+	}
+}
+
+func TestResolvePathItemOperationOrder(t *testing.T) {
+	oas := loadTestSpecFiles(t, externalRefFiles)
+
+	// The same order every time, like list_endpoints
+	for i := 0; i < 10; i++ {
+		var result struct {
+			Operations []struct {
+				Method string `json:"method"`
+			} `json:"operations"`
+		}
+		callToolJSON(t, oas.resolveRefHandler, map[string]interface{}{"ref": "#/paths/~1pets"}, &result)
+		methods := []string{}
+		for _, operation := range result.Operations {
+			methods = append(methods, operation.Method)
+		}
+		if strings.Join(methods, ",") != "GET,POST,DELETE" {
+			t.Fatalf("operations listed as %v", methods)
+		}
+	}
+}
+
+func TestRefTrailExplain(t *testing.T) {
+	root, _ := url.Parse("/api/openapi.yaml")
+	pets, _ := url.Parse("/api/schemas/pets.yaml")
+	newTrail := func() *refTrail {
+		trail := &refTrail{root: root.String()}
+		trail.addDocument(root, []byte("paths:\n  /pets: {$ref: 'schemas/pets.yaml#/PetPath'}\nlocal: {$ref: '#/components/schemas/Pet'}\n"))
+		trail.addDocument(pets, []byte("PetPath:\n  get: {$ref: '../common/ops.yaml#/Get'}\nPet: {$ref: 'owner.yaml#/Owner'}\n"))
+		return trail
+	}
+	viaPets := `$ref "schemas/pets.yaml#/PetPath" in /api/openapi.yaml -> `
+
+	// A document that could not be read
+	trail := newTrail()
+	trail.failed = "/api/common/ops.yaml"
+	if got, want := trail.explain(errors.New("open failed")), viaPets+`$ref "../common/ops.yaml#/Get" in /api/schemas/pets.yaml`; got != want {
+		t.Errorf("unreadable document:\n%s\nwant\n%s", got, want)
+	}
+
+	// A ref named by the loader's error
+	trail = newTrail()
+	if got, want := trail.explain(errors.New(`error resolving reference "owner.yaml#/Owner"`)), viaPets+`$ref "owner.yaml#/Owner" in /api/schemas/pets.yaml`; got != want {
+		t.Errorf("ref named by the loader:\n%s\nwant\n%s", got, want)
+	}
+
+	// A pointer missing from a document that was read, which the loader does not name
+	trail = &refTrail{root: root.String()}
+	trail.addDocument(root, []byte("paths:\n  /pets: {$ref: 'schemas/pets.yaml#/PetPath'}\n"))
+	trail.addDocument(pets, []byte("Other: {type: string}\n"))
+	if got, want := trail.explain(errors.New("bad data")), `$ref "schemas/pets.yaml#/PetPath" in /api/openapi.yaml`; got != want {
+		t.Errorf("missing pointer:\n%s\nwant\n%s", got, want)
+	}
+
+	// Local refs are not part of the trail
+	if got := newTrail().explain(errors.New(`error resolving reference "#/components/schemas/Pet"`)); got != "" {
+		t.Errorf("local ref explained as %s", got)
+	}
+}
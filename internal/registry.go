@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const DefaultSpecName = "default"
+
+// SpecSource names one OpenAPI spec to load
+type SpecSource struct {
+	Name    string            `json:"name"`
+	URL     string            `json:"url"`               // URL or file path
+	Headers map[string]string `json:"headers,omitempty"` // extra headers for downloading this spec
+}
+
+// specsFile is the format of the file referenced by OPENAPI_SPECS_FILE
+type specsFile struct {
+	Specs []SpecSource `json:"specs"`
+}
+
+// GetSpecSources reads the specs to serve from the environment. OPENAPI_SPECS_FILE points to a JSON
+// file listing named specs, OPENAPI_SPECS holds a comma separated list of name=source pairs and
+// OPENAPI_SPEC_URL configures a single spec on its own.
+func GetSpecSources() ([]SpecSource, error) {
+	var sources []SpecSource
+
+	if specsPath := os.Getenv("OPENAPI_SPECS_FILE"); specsPath != "" {
+		data, err := os.ReadFile(specsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read specs file: %w", err)
+		}
+		var file specsFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse specs file: %w", err)
+		}
+		sources = append(sources, file.Specs...)
+	}
+
+	if specs := os.Getenv("OPENAPI_SPECS"); specs != "" {
+		for _, entry := range strings.Split(specs, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			name, source, found := strings.Cut(entry, "=")
+			if !found {
+				return nil, fmt.Errorf("invalid OPENAPI_SPECS entry %q, expected name=source", entry)
+			}
+			sources = append(sources, SpecSource{Name: strings.TrimSpace(name), URL: strings.TrimSpace(source)})
+		}
+	}
+
+	if specSource := os.Getenv("OPENAPI_SPEC_URL"); specSource != "" {
+		// Ignoring either setting would serve other specs than the user asked for
+		if len(sources) > 0 {
+			return nil, fmt.Errorf("OPENAPI_SPEC_URL cannot be combined with OPENAPI_SPECS or OPENAPI_SPECS_FILE, list the spec there with a name instead")
+		}
+		sources = append(sources, SpecSource{Name: DefaultSpecName, URL: specSource})
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("OPENAPI_SPEC_URL is not specified. Set it to a file path or http/https url, or configure several specs with OPENAPI_SPECS or OPENAPI_SPECS_FILE")
+	}
+
+	seen := map[string]bool{}
+	for _, source := range sources {
+		if source.Name == "" || source.URL == "" {
+			return nil, fmt.Errorf("every spec needs a name and a url")
+		}
+		if seen[source.Name] {
+			return nil, fmt.Errorf("duplicate spec name: %s", source.Name)
+		}
+		seen[source.Name] = true
+	}
+
+	return sources, nil
+}
+
+// SpecRegistry holds every spec served by one MCP server. The first spec is the default for tool
+// calls that do not name one.
+type SpecRegistry struct {
+	servers []*OpenAPIServer
+	byName  map[string]*OpenAPIServer
+}
+
+func NewSpecRegistry(sources []SpecSource, cacheDir string, download DownloadConfig) *SpecRegistry {
+	registry := &SpecRegistry{
+		byName: map[string]*OpenAPIServer{},
+	}
+	for _, source := range sources {
+		oas := NewOpenAPIServer(source.URL, cacheDir)
+		oas.name = source.Name
+
+		// Per-spec headers are added on top of the global download configuration. Both are only
+		// sent to the origin of the spec itself and the configured origins.
+		specDownload := download
+		specDownload.Headers = download.Headers.Clone()
+		for name, value := range source.Headers {
+			specDownload.Headers.Set(name, value)
+		}
+		specDownload.Origins = slices.Clone(download.Origins)
+		if u, err := url.Parse(source.URL); err == nil && isURL(source.URL) {
+			specDownload.Origins = append(specDownload.Origins, urlOrigin(u))
+		}
+		oas.cache.SetDownloadConfig(specDownload)
+
+		registry.servers = append(registry.servers, oas)
+		registry.byName[source.Name] = oas
+	}
+	return registry
+}
+
+// SetCategoryStrategy configures how every spec groups its endpoints into categories
+func (r *SpecRegistry) SetCategoryStrategy(strategy CategoryStrategy) {
+	for _, oas := range r.servers {
+		oas.categoryStrategy = strategy
+	}
+}
+
+// SetCallConfig configures the call_endpoint tool of every spec
+func (r *SpecRegistry) SetCallConfig(config CallConfig) {
+	for _, oas := range r.servers {
+		oas.calls = config
+	}
+}
+
+// LoadAll loads every spec in the registry
+func (r *SpecRegistry) LoadAll() error {
+	for _, oas := range r.servers {
+		if err := oas.LoadSpec(); err != nil {
+			return fmt.Errorf("spec %s: %w", oas.name, err)
+		}
+	}
+	return nil
+}
+
+// Servers returns the specs in configuration order
+func (r *SpecRegistry) Servers() []*OpenAPIServer {
+	return r.servers
+}
+
+// Default returns the spec used when a tool call does not name one
+func (r *SpecRegistry) Default() *OpenAPIServer {
+	return r.servers[0]
+}
+
+// Get returns the named spec, or the default spec for an empty name
+func (r *SpecRegistry) Get(name string) (*OpenAPIServer, error) {
+	if name == "" {
+		return r.Default(), nil
+	}
+	oas, exists := r.byName[name]
+	if !exists {
+		names := make([]string, 0, len(r.servers))
+		for _, candidate := range r.servers {
+			names = append(names, candidate.name)
+		}
+		return nil, fmt.Errorf("spec not found: %s. Available specs: %s", name, strings.Join(names, ", "))
+	}
+	return oas, nil
+}
+
+// specHandler is a tool handler bound to a single spec, matching the OpenAPIServer handler methods
+type specHandler func(oas *OpenAPIServer, ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// dispatch routes a tool call to the spec named by its optional "spec" argument
+func (r *SpecRegistry) dispatch(handler specHandler) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		oas, err := r.Get(request.GetString("spec", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// Hold the spec for the whole call so a concurrent reload cannot swap it halfway
+		oas.mu.RLock()
+		defer oas.mu.RUnlock()
+
+		return handler(oas, ctx, request)
+	}
+}
+
+// dispatchUnlocked routes a tool call like dispatch but leaves locking the spec to the handler, for
+// handlers that wait on the network and only hold the lock while reading the spec
+func (r *SpecRegistry) dispatchUnlocked(handler specHandler) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		oas, err := r.Get(request.GetString("spec", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return handler(oas, ctx, request)
+	}
+}
+
+func (r *SpecRegistry) listSpecsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	specs := make([]map[string]interface{}, 0, len(r.servers))
+	for i, oas := range r.servers {
+		oas.mu.RLock()
+		operationCount := 0
+		for _, pathItem := range oas.spec.Paths.Map() {
+			operationCount += len(pathItem.Operations())
+		}
+		specs = append(specs, map[string]interface{}{
+			"name":       oas.name,
+			"source":     oas.specSource,
+			"title":      oas.spec.Info.Title,
+			"version":    oas.spec.Info.Version,
+			"operations": operationCount,
+			"default":    i == 0,
+		})
+		oas.mu.RUnlock()
+	}
+	return JSONResponse(specs)
+}
@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func setSpecEnv(t *testing.T, specURL, specs, specsFile string) {
+	t.Helper()
+	t.Setenv("OPENAPI_SPEC_URL", specURL)
+	t.Setenv("OPENAPI_SPECS", specs)
+	t.Setenv("OPENAPI_SPECS_FILE", specsFile)
+}
+
+func TestGetSpecSources(t *testing.T) {
+	specsFile := writeTempFile(t, "specs.json", `{"specs": [
+		{"name": "users", "url": "https://users.example.com/openapi.json", "headers": {"X-Tenant": "acme"}},
+		{"name": "orders", "url": "/specs/orders.yaml"}
+	]}`)
+
+	tests := []struct {
+		name                      string
+		specURL, specs, specsFile string
+		want                      []SpecSource
+		err                       string
+	}{
+		{
+			name:    "single spec",
+			specURL: "/specs/api.yaml",
+			want:    []SpecSource{{Name: DefaultSpecName, URL: "/specs/api.yaml"}},
+		},
+		{
+			name:  "name=source pairs",
+			specs: " users = https://users.example.com/openapi.json ,, orders=/specs/orders.yaml",
+			want: []SpecSource{
+				{Name: "users", URL: "https://users.example.com/openapi.json"},
+				{Name: "orders", URL: "/specs/orders.yaml"},
+			},
+		},
+		{
+			name:      "specs file",
+			specsFile: specsFile,
+			want: []SpecSource{
+				{Name: "users", URL: "https://users.example.com/openapi.json", Headers: map[string]string{"X-Tenant": "acme"}},
+				{Name: "orders", URL: "/specs/orders.yaml"},
+			},
+		},
+		{
+			name:      "specs file and pairs",
+			specs:     "billing=/specs/billing.yaml",
+			specsFile: specsFile,
+			want: []SpecSource{
+				{Name: "users", URL: "https://users.example.com/openapi.json", Headers: map[string]string{"X-Tenant": "acme"}},
+				{Name: "orders", URL: "/specs/orders.yaml"},
+				{Name: "billing", URL: "/specs/billing.yaml"},
+			},
+		},
+		{name: "nothing configured", err: "OPENAPI_SPEC_URL is not specified"},
+		{name: "pair without name", specs: "users", err: `invalid OPENAPI_SPECS entry "users"`},
+		{name: "empty name", specs: "=/specs/api.yaml", err: "every spec needs a name and a url"},
+		{name: "empty source", specs: "users=", err: "every spec needs a name and a url"},
+		{name: "duplicate name", specs: "users=/a.yaml,users=/b.yaml", err: "duplicate spec name: users"},
+		{name: "duplicate across file and pairs", specs: "users=/a.yaml", specsFile: specsFile, err: "duplicate spec name: users"},
+		{name: "missing specs file", specsFile: "/does/not/exist.json", err: "failed to read specs file"},
+		{name: "invalid specs file", specsFile: writeTempFile(t, "broken.json", `{"specs": [`), err: "failed to parse specs file"},
+		{name: "spec url with pairs", specURL: "/specs/api.yaml", specs: "users=/a.yaml", err: "OPENAPI_SPEC_URL cannot be combined"},
+		{name: "spec url with specs file", specURL: "/specs/api.yaml", specsFile: specsFile, err: "OPENAPI_SPEC_URL cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setSpecEnv(t, tt.specURL, tt.specs, tt.specsFile)
+			sources, err := GetSpecSources()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sources) != len(tt.want) {
+				t.Fatalf("sources = %+v, want %+v", sources, tt.want)
+			}
+			for i, source := range sources {
+				want := tt.want[i]
+				if source.Name != want.Name || source.URL != want.URL || len(source.Headers) != len(want.Headers) {
+					t.Errorf("source %d = %+v, want %+v", i, source, want)
+				}
+				for name, value := range want.Headers {
+					if source.Headers[name] != value {
+						t.Errorf("source %d header %s = %q, want %q", i, name, source.Headers[name], value)
+					}
+				}
+			}
+		})
+	}
+}
+
+// loadTestRegistry serves two specs, users first so it is the default
+func loadTestRegistry(t *testing.T) *SpecRegistry {
+	t.Helper()
+	registry := NewSpecRegistry([]SpecSource{
+		{Name: "users", URL: writeTempFile(t, "users.yaml", `
+openapi: 3.0.3
+info: {title: Users, version: "1"}
+paths:
+  /users:
+    get: {responses: {"200": {description: ok}}}
+    post: {responses: {"201": {description: created}}}
+`)},
+		{Name: "orders", URL: writeTempFile(t, "orders.yaml", `
+openapi: 3.0.3
+info: {title: Orders, version: "2"}
+paths:
+  /orders:
+    get: {responses: {"200": {description: ok}}}
+`)},
+	}, t.TempDir(), DefaultDownloadConfig())
+	if err := registry.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func TestRegistryDispatch(t *testing.T) {
+	registry := loadTestRegistry(t)
+	handler := registry.dispatch((*OpenAPIServer).getSpecInfoHandler)
+
+	for spec, title := range map[string]string{"": "Users", "users": "Users", "orders": "Orders"} {
+		var info struct {
+			Title string `json:"title"`
+		}
+		args := map[string]interface{}{}
+		if spec != "" {
+			args["spec"] = spec
+		}
+		callToolJSON(t, handler, args, &info)
+		if info.Title != title {
+			t.Errorf("spec %q was routed to %s, want %s", spec, info.Title, title)
+		}
+	}
+
+	text, isError := callTool(t, handler, map[string]interface{}{"spec": "billing"})
+	if !isError || !strings.Contains(text, "spec not found: billing. Available specs: users, orders") {
+		t.Fatalf("unknown spec: %s", text)
+	}
+}
+
+func TestListSpecs(t *testing.T) {
+	registry := loadTestRegistry(t)
+
+	var specs []struct {
+		Name       string `json:"name"`
+		Title      string `json:"title"`
+		Version    string `json:"version"`
+		Operations int    `json:"operations"`
+		Default    bool   `json:"default"`
+	}
+	callToolJSON(t, registry.listSpecsHandler, map[string]interface{}{}, &specs)
+	if len(specs) != 2 {
+		t.Fatalf("list_specs = %+v", specs)
+	}
+	users, orders := specs[0], specs[1]
+	if users.Name != "users" || users.Title != "Users" || users.Operations != 2 || !users.Default {
+		t.Errorf("users = %+v", users)
+	}
+	if orders.Name != "orders" || orders.Version != "2" || orders.Operations != 1 || orders.Default {
+		t.Errorf("orders = %+v", orders)
+	}
+}
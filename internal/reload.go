@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GetReloadInterval reads how often specs are checked for changes. Reloading is disabled when
+// OPENAPI_RELOAD_INTERVAL is unset or not a positive duration.
+func GetReloadInterval() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("OPENAPI_RELOAD_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return 0
+	}
+	return interval
+}
+
+// WatchSpecs periodically reloads every spec in the registry until the context is cancelled.
+// Clients are sent a tools/list_changed notification whenever a spec changes. A spec that fails to
+// reload keeps being served in its previous version.
+func WatchSpecs(ctx context.Context, registry *SpecRegistry, mcpServer *server.MCPServer, interval time.Duration) {
+	log.Printf("Checking OpenAPI specs for changes every %s", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, oas := range registry.Servers() {
+				changed, err := oas.ReloadSpec()
+				if err != nil {
+					log.Printf("Warning: failed to reload spec %s: %v", oas.name, err)
+					continue
+				}
+				if changed {
+					log.Printf("Reloaded OpenAPI spec %s from %s", oas.name, oas.specSource)
+					mcpServer.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const reloadSpec = `
+openapi: 3.0.3
+info: {title: reload, version: "%s"}
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: 'schemas.yaml#/Pet'}
+`
+
+// writeFile writes a file and moves its modification time forward, so edits are seen even on file
+// systems with coarse timestamps
+func writeFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadSpec(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "openapi.yaml")
+	schemas := filepath.Join(dir, "schemas.yaml")
+	now := time.Now()
+	writeFile(t, root, fmt.Sprintf(reloadSpec, "1"), now)
+	writeFile(t, schemas, "Pet: {type: object, properties: {name: {type: string}}}\n", now)
+
+	oas := NewOpenAPIServer(root, t.TempDir())
+	if err := oas.LoadSpec(); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := oas.ReloadSpec(); changed || err != nil {
+		t.Fatalf("untouched spec: changed=%v err=%v", changed, err)
+	}
+
+	// Touching a file without changing it is not a change
+	writeFile(t, root, fmt.Sprintf(reloadSpec, "1"), now.Add(time.Second))
+	if changed, err := oas.ReloadSpec(); changed || err != nil {
+		t.Fatalf("touched spec: changed=%v err=%v", changed, err)
+	}
+
+	// An edit to a referenced document is picked up
+	writeFile(t, schemas, "Pet: {type: object, properties: {name: {type: string}, age: {type: integer}}}\n", now.Add(2*time.Second))
+	if changed, err := oas.ReloadSpec(); !changed || err != nil {
+		t.Fatalf("edited external document: changed=%v err=%v", changed, err)
+	}
+	pet := oas.spec.Paths.Value("/pets").Get.Responses.Status(200).Value.Content.Get("application/json").Schema.Value
+	if pet.Properties["age"] == nil {
+		t.Fatal("the reloaded spec does not have the new property")
+	}
+
+	// A broken save keeps the previous spec and is retried until it is fixed
+	writeFile(t, root, "openapi: [", now.Add(3*time.Second))
+	for i := 0; i < 2; i++ {
+		if _, err := oas.ReloadSpec(); err == nil {
+			t.Fatalf("attempt %d: expected an error for the broken spec", i)
+		}
+	}
+	if oas.spec.Info.Version != "1" {
+		t.Fatalf("version = %s, want the previous spec", oas.spec.Info.Version)
+	}
+
+	// Fixing the file with the same modification time still gets it loaded
+	writeFile(t, root, fmt.Sprintf(reloadSpec, "2"), now.Add(3*time.Second))
+	if changed, err := oas.ReloadSpec(); !changed || err != nil {
+		t.Fatalf("fixed spec: changed=%v err=%v", changed, err)
+	}
+	if oas.spec.Info.Version != "2" {
+		t.Fatalf("version = %s, want 2", oas.spec.Info.Version)
+	}
+}
+
+func TestReloadRemoteSpecSkipsParsingUnchangedContent(t *testing.T) {
+	version := "1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every reload downloads the spec again
+		w.Header().Set("Cache-Control", "no-cache")
+		switch r.URL.Path {
+		case "/openapi.yaml":
+			w.Write([]byte(fmt.Sprintf(reloadSpec, version)))
+		case "/schemas.yaml":
+			w.Write([]byte("Pet: {type: object}\n"))
+		}
+	}))
+	defer server.Close()
+
+	oas := NewOpenAPIServer(server.URL+"/openapi.yaml", t.TempDir())
+	if err := oas.LoadSpec(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Parsing logs the validation warnings of the spec, which has no operationIds
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+	if changed, err := oas.ReloadSpec(); changed || err != nil {
+		t.Fatalf("unchanged spec: changed=%v err=%v", changed, err)
+	}
+	if strings.Contains(logged.String(), "validation errors") {
+		t.Fatalf("the unchanged spec was parsed again:\n%s", logged.String())
+	}
+
+	version = "2"
+	if changed, err := oas.ReloadSpec(); !changed || err != nil {
+		t.Fatalf("edited spec: changed=%v err=%v", changed, err)
+	}
+	if oas.spec.Info.Version != "2" {
+		t.Fatalf("version = %s, want 2", oas.spec.Info.Version)
+	}
+}
@@ -0,0 +1,894 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultServerURL is used when the spec declares no servers or only relative ones
+	DefaultServerURL = "http://localhost"
+	// CredentialVariablePrefix starts every credential variable, which continues with the spec name
+	// and the security scheme, e.g. OPENAPI_CRED_PETSTORE__BEARER_AUTH_TOKEN
+	CredentialVariablePrefix = "OPENAPI_CRED_"
+)
+
+// requestText mixes literal text with credential placeholders, so the same request can be printed
+// as a shell command reading environment variables or sent with real credentials
+type requestText []requestTextPart
+
+type requestTextPart struct {
+	Literal  string
+	Variable string
+}
+
+func literalText(text string) requestText {
+	return requestText{{Literal: text}}
+}
+
+// resolve fills in the placeholders using lookup
+func (t requestText) resolve(lookup func(variable string) string) string {
+	var b strings.Builder
+	for _, part := range t {
+		if part.Variable != "" {
+			b.WriteString(lookup(part.Variable))
+		} else {
+			b.WriteString(part.Literal)
+		}
+	}
+	return b.String()
+}
+
+// String shows placeholders as ${VARIABLE}
+func (t requestText) String() string {
+	return t.resolve(func(variable string) string { return "${" + variable + "}" })
+}
+
+// shellQuote quotes the text for POSIX shells. Text with placeholders is double quoted so the
+// variables expand; everything else is single quoted.
+func (t requestText) shellQuote() string {
+	hasVariable := false
+	for _, part := range t {
+		if part.Variable != "" {
+			hasVariable = true
+		}
+	}
+	if !hasVariable {
+		return "'" + strings.ReplaceAll(t.String(), "'", `'\''`) + "'"
+	}
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	var b strings.Builder
+	b.WriteString(`"`)
+	for _, part := range t {
+		if part.Variable != "" {
+			b.WriteString("${" + part.Variable + "}")
+		} else {
+			b.WriteString(escaper.Replace(part.Literal))
+		}
+	}
+	b.WriteString(`"`)
+	return b.String()
+}
+
+// preparedHeader is a request header whose value may hold credential placeholders
+type preparedHeader struct {
+	Name  string
+	Value requestText
+}
+
+// credentialPlaceholder documents an environment variable a prepared request expects
+type credentialPlaceholder struct {
+	Scheme   string `json:"scheme"`
+	Type     string `json:"type"`
+	Variable string `json:"variable"`
+	Usage    string `json:"usage"`
+}
+
+// preparedRequest is a concrete request for an operation, with parameters serialized and
+// credentials left as placeholders
+type preparedRequest struct {
+	Method      string
+	URL         requestText
+	Headers     []preparedHeader
+	BasicAuth   requestText // user:password for HTTP basic authentication
+	ContentType string
+	Body        []byte
+	// FormFields hold the fields of form and multipart bodies for tools that send them field by field
+	FormFields [][2]string
+	// PathParams are the serialized path parameter values by name
+	PathParams map[string]string
+
+	Credentials []credentialPlaceholder
+	// Generated lists the values taken from examples because the caller did not provide them
+	Generated []string
+	Warnings  []string
+}
+
+// requestInput holds the caller's values for building a request
+type requestInput struct {
+	Parameters      map[string]interface{}
+	Body            interface{}
+	MediaType       string
+	Server          string
+	ServerVariables map[string]interface{}
+	Headers         map[string]interface{}
+	// NoExamples sends only the values the caller provided: missing required values are not
+	// filled from examples and undocumented media types are accepted
+	NoExamples bool
+	// NoCredentials leaves out the credentials of the security requirements
+	NoCredentials bool
+}
+
+// requestInputFromArguments reads the build arguments shared by the request tools
+func requestInputFromArguments(request mcp.CallToolRequest) (requestInput, error) {
+	args := request.GetArguments()
+	input := requestInput{
+		Body:      args["body"],
+		MediaType: request.GetString("media_type", ""),
+		Server:    request.GetString("server", ""),
+	}
+
+	for _, key := range []string{"parameters", "server_variables", "headers"} {
+		raw, exists := args[key]
+		if !exists || raw == nil {
+			continue
+		}
+		values, ok := raw.(map[string]interface{})
+		if !ok {
+			return input, fmt.Errorf("%s must be an object", key)
+		}
+		switch key {
+		case "parameters":
+			input.Parameters = values
+		case "server_variables":
+			input.ServerVariables = values
+		default:
+			input.Headers = values
+		}
+	}
+
+	return input, nil
+}
+
+// buildRequest prepares a request for an operation from the caller's values. Required values that
+// are missing are taken from the spec's examples so the result is ready to send.
+func (oas *OpenAPIServer) buildRequest(path, method string, pathItem *openapi3.PathItem, operation *openapi3.Operation, input requestInput) (*preparedRequest, error) {
+	prepared := &preparedRequest{
+		Method:      strings.ToUpper(method),
+		PathParams:  map[string]string{},
+		Credentials: []credentialPlaceholder{},
+		Generated:   []string{},
+		Warnings:    []string{},
+	}
+	generator := oas.newExampleGenerator(0, true)
+
+	baseURL, err := oas.serverURL(pathItem, operation, input.Server, input.ServerVariables, prepared)
+	if err != nil {
+		return nil, err
+	}
+
+	used := map[string]bool{}
+	resolvedPath := path
+	query := requestText{}
+	cookies := []requestText{}
+
+	for _, declared := range effectiveParameters(pathItem, operation) {
+		param := declared.Ref.Value
+
+		value, provided := input.Parameters[param.Name]
+		if provided {
+			used[param.Name] = true
+		} else if param.Required && !input.NoExamples {
+			value = parameterExample(param, generator)
+			prepared.Generated = append(prepared.Generated, param.In+" parameter "+param.Name)
+		} else {
+			continue
+		}
+
+		serialized, err := serializeParameter(param, value)
+		if err != nil {
+			return nil, err
+		}
+		// Empty exploded arrays and objects have no pairs to send, which would leave a bare
+		// separator behind
+		if serialized == "" && (param.In == openapi3.ParameterInQuery || param.In == openapi3.ParameterInCookie) {
+			prepared.Warnings = append(prepared.Warnings, fmt.Sprintf("%s parameter %s is empty and was left out", param.In, param.Name))
+			continue
+		}
+
+		switch param.In {
+		case openapi3.ParameterInPath:
+			resolvedPath = strings.ReplaceAll(resolvedPath, "{"+param.Name+"}", serialized)
+			prepared.PathParams[param.Name] = serialized
+		case openapi3.ParameterInQuery:
+			query = appendQuery(query, literalText(serialized))
+		case openapi3.ParameterInHeader:
+			prepared.Headers = append(prepared.Headers, preparedHeader{Name: param.Name, Value: literalText(serialized)})
+		case openapi3.ParameterInCookie:
+			cookies = append(cookies, literalText(serialized))
+		}
+	}
+
+	for _, name := range sortedKeys(input.Parameters) {
+		if !used[name] {
+			prepared.Warnings = append(prepared.Warnings, fmt.Sprintf("parameter %s is not declared by the operation and was ignored", name))
+		}
+	}
+
+	for _, name := range sortedKeys(input.Headers) {
+		prepared.Headers = append(prepared.Headers, preparedHeader{Name: name, Value: literalText(primitiveString(input.Headers[name]))})
+	}
+
+	// Credentials for the first security alternative, as placeholders
+	requirements, _ := effectiveSecurity(oas.spec, operation)
+	if len(requirements) > 0 && !input.NoCredentials {
+		for _, name := range sortedKeys(requirements[0]) {
+			query, cookies = oas.addCredentials(prepared, name, query, cookies)
+		}
+		if len(requirements) > 1 {
+			prepared.Warnings = append(prepared.Warnings, "the operation accepts several security alternatives, the first one is used")
+		}
+	}
+
+	if len(cookies) > 0 {
+		cookie := append(requestText{}, cookies[0]...)
+		for _, next := range cookies[1:] {
+			cookie = append(append(cookie, requestTextPart{Literal: "; "}), next...)
+		}
+		prepared.Headers = append(prepared.Headers, preparedHeader{Name: "Cookie", Value: cookie})
+	}
+
+	prepared.URL = append(literalText(strings.TrimSuffix(baseURL, "/")+resolvedPath), query...)
+
+	if err := oas.prepareBody(prepared, operation, input, generator); err != nil {
+		return nil, err
+	}
+	prepared.Warnings = append(prepared.Warnings, generator.warnings...)
+
+	return prepared, nil
+}
+
+func appendQuery(query requestText, pair requestText) requestText {
+	separator := "&"
+	if len(query) == 0 {
+		separator = "?"
+	}
+	return append(append(query, requestTextPart{Literal: separator}), pair...)
+}
+
+// parameterExample returns a documented or synthesized value for a required parameter
+func parameterExample(param *openapi3.Parameter, generator *exampleGenerator) interface{} {
+	if param.Example != nil {
+		return param.Example
+	}
+	for _, name := range sortedKeys(param.Examples) {
+		if example := param.Examples[name]; example != nil && example.Value != nil && example.Value.Value != nil {
+			return example.Value.Value
+		}
+	}
+	if param.Schema != nil {
+		value, _ := generator.generate(param.Schema, 0)
+		return value
+	}
+	for _, media := range param.Content {
+		value, _ := mediaTypeExample(media, generator)
+		return value
+	}
+	return ""
+}
+
+// effectiveServers returns the servers of an operation, which override those of its path, which
+// override those of the spec
+func effectiveServers(spec *openapi3.T, pathItem *openapi3.PathItem, operation *openapi3.Operation) openapi3.Servers {
+	if operation != nil && operation.Servers != nil && len(*operation.Servers) > 0 {
+		return *operation.Servers
+	}
+	if pathItem != nil && len(pathItem.Servers) > 0 {
+		return pathItem.Servers
+	}
+	return spec.Servers
+}
+
+// serverURL picks the server by index or URL, or takes an explicit base URL, and substitutes
+// its variables. Relative server URLs are resolved against the spec location when it is remote.
+func (oas *OpenAPIServer) serverURL(pathItem *openapi3.PathItem, operation *openapi3.Operation, selected string, variables map[string]interface{}, prepared *preparedRequest) (string, error) {
+	servers := effectiveServers(oas.spec, pathItem, operation)
+
+	var server *openapi3.Server
+	if selected == "" {
+		if len(servers) > 0 {
+			server = servers[0]
+		}
+	} else if index, err := strconv.Atoi(selected); err == nil {
+		if index < 0 || index >= len(servers) {
+			return "", fmt.Errorf("Server index %d out of range, the operation has %d servers", index, len(servers))
+		}
+		server = servers[index]
+	} else {
+		for _, candidate := range servers {
+			if candidate.URL == selected {
+				server = candidate
+				break
+			}
+		}
+		if server == nil {
+			if !isURL(selected) {
+				return "", fmt.Errorf("Server not found: %s. Pass a server index, a server URL from the spec or an absolute base URL", selected)
+			}
+			return selected, nil
+		}
+	}
+
+	if server == nil {
+		prepared.Warnings = append(prepared.Warnings, "the spec declares no servers, "+DefaultServerURL+" is used as the base URL")
+		return DefaultServerURL, nil
+	}
+
+	serverURL := server.URL
+	for _, name := range sortedKeys(server.Variables) {
+		variable := server.Variables[name]
+		value := variable.Default
+		if provided, exists := variables[name]; exists {
+			value = fmt.Sprint(provided)
+			if len(variable.Enum) > 0 && !slices.Contains(variable.Enum, value) {
+				return "", fmt.Errorf("Invalid value %s for server variable %s. Expected one of: %s", value, name, strings.Join(variable.Enum, ", "))
+			}
+		}
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", value)
+	}
+
+	if !isURL(serverURL) {
+		base := DefaultServerURL
+		if isURL(oas.specSource) {
+			base = oas.specSource
+		} else {
+			prepared.Warnings = append(prepared.Warnings, fmt.Sprintf("server URL %s is relative, %s is used as the host", server.URL, DefaultServerURL))
+		}
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		relative, err := url.Parse(serverURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid server URL %s: %w", serverURL, err)
+		}
+		serverURL = baseURL.ResolveReference(relative).String()
+	}
+
+	return serverURL, nil
+}
+
+// credentialVariable names the environment variable for a security scheme of a spec, e.g. spec
+// petstore and scheme bearerAuth -> OPENAPI_CRED_PETSTORE__BEARER_AUTH_TOKEN. Neither name part can
+// hold a double underscore, so specs never share a variable.
+func credentialVariable(spec, scheme, suffix string) string {
+	return credentialPrefix(spec) + variableName(scheme, suffix)
+}
+
+// credentialPrefix starts the credential variables of a spec; only these are ever read
+func credentialPrefix(spec string) string {
+	return CredentialVariablePrefix + variableName(spec, "") + "__"
+}
+
+// variableName turns a name into upper snake case, e.g. bearerAuth -> BEARER_AUTH
+func variableName(name, suffix string) string {
+	parts := []string{}
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		parts = append(parts, splitIdentifier(word)...)
+	}
+	if suffix != "" {
+		parts = append(parts, suffix)
+	}
+	return strings.ToUpper(strings.Join(parts, "_"))
+}
+
+// addCredentials adds placeholders for one security scheme of the selected requirement
+func (oas *OpenAPIServer) addCredentials(prepared *preparedRequest, name string, query requestText, cookies []requestText) (requestText, []requestText) {
+	scheme := oas.securityScheme(name)
+	if scheme == nil {
+		prepared.Warnings = append(prepared.Warnings, fmt.Sprintf("security scheme %s is not declared in components", name))
+		return query, cookies
+	}
+
+	placeholder := func(variable, usage string) {
+		prepared.Credentials = append(prepared.Credentials, credentialPlaceholder{
+			Scheme:   name,
+			Type:     scheme.Type,
+			Variable: variable,
+			Usage:    usage,
+		})
+	}
+
+	switch scheme.Type {
+	case "http":
+		switch strings.ToLower(scheme.Scheme) {
+		case "basic":
+			user, password := credentialVariable(oas.name, name, "USER"), credentialVariable(oas.name, name, "PASSWORD")
+			prepared.BasicAuth = requestText{{Variable: user}, {Literal: ":"}, {Variable: password}}
+			placeholder(user, "basic auth user name")
+			placeholder(password, "basic auth password")
+		default:
+			variable := credentialVariable(oas.name, name, "TOKEN")
+			authScheme := scheme.Scheme
+			if strings.EqualFold(authScheme, "bearer") {
+				authScheme = "Bearer"
+			}
+			prepared.Headers = append(prepared.Headers, preparedHeader{
+				Name:  "Authorization",
+				Value: requestText{{Literal: authScheme + " "}, {Variable: variable}},
+			})
+			placeholder(variable, "Authorization header "+authScheme+" credentials")
+		}
+	case "apiKey":
+		variable := credentialVariable(oas.name, name, "")
+		switch scheme.In {
+		case openapi3.ParameterInHeader:
+			prepared.Headers = append(prepared.Headers, preparedHeader{Name: scheme.Name, Value: requestText{{Variable: variable}}})
+			placeholder(variable, "API key sent in the "+scheme.Name+" header")
+		case openapi3.ParameterInQuery:
+			query = appendQuery(query, requestText{{Literal: queryEscape(scheme.Name) + "="}, {Variable: variable}})
+			placeholder(variable, "API key sent as the "+scheme.Name+" query parameter")
+		case openapi3.ParameterInCookie:
+			cookies = append(cookies, requestText{{Literal: scheme.Name + "="}, {Variable: variable}})
+			placeholder(variable, "API key sent as the "+scheme.Name+" cookie")
+		}
+	case "oauth2", "openIdConnect":
+		variable := credentialVariable(oas.name, name, "TOKEN")
+		prepared.Headers = append(prepared.Headers, preparedHeader{
+			Name:  "Authorization",
+			Value: requestText{{Literal: "Bearer "}, {Variable: variable}},
+		})
+		placeholder(variable, "access token from the "+scheme.Type+" flow")
+	default:
+		prepared.Warnings = append(prepared.Warnings, fmt.Sprintf("security scheme %s of type %s cannot be added to the command", name, scheme.Type))
+	}
+
+	return query, cookies
+}
+
+// prepareBody encodes the caller's body, or an example when a required body is missing
+func (oas *OpenAPIServer) prepareBody(prepared *preparedRequest, operation *openapi3.Operation, input requestInput, generator *exampleGenerator) error {
+	if operation.RequestBody == nil || operation.RequestBody.Value == nil {
+		if input.Body != nil {
+			return fmt.Errorf("%s does not take a request body", prepared.Method)
+		}
+		return nil
+	}
+	requestBody := operation.RequestBody.Value
+
+	if input.Body == nil && (!requestBody.Required || input.NoExamples) {
+		return nil
+	}
+
+	mediaType, media, err := selectMediaType(requestBody.Content, input.MediaType)
+	if err != nil {
+		// An undocumented media type is the caller's to send when the request is taken as given
+		if !input.NoExamples || input.MediaType == "" {
+			return err
+		}
+		mediaType = input.MediaType
+	}
+	prepared.ContentType = mediaType
+
+	body := input.Body
+	if body == nil {
+		body, _ = mediaTypeExample(media, generator)
+		prepared.Generated = append(prepared.Generated, "request body")
+	}
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded" || strings.HasPrefix(mediaType, "multipart/"):
+		fields, ok := body.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("a %s body must be an object of form fields", mediaType)
+		}
+		values := url.Values{}
+		for _, name := range sortedKeys(fields) {
+			value := formValue(fields[name])
+			prepared.FormFields = append(prepared.FormFields, [2]string{name, value})
+			values.Add(name, value)
+		}
+		if mediaType == "application/x-www-form-urlencoded" {
+			prepared.Body = []byte(values.Encode())
+		}
+	default:
+		if text, isText := body.(string); isText {
+			prepared.Body = []byte(text)
+		} else {
+			data, err := json.Marshal(body)
+			if err != nil {
+				return fmt.Errorf("failed to encode body: %w", err)
+			}
+			prepared.Body = data
+		}
+	}
+
+	// A Content-Type header from the caller wins over the selected media type
+	for _, header := range prepared.Headers {
+		if strings.EqualFold(header.Name, "Content-Type") {
+			return nil
+		}
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		prepared.Headers = append(prepared.Headers, preparedHeader{Name: "Content-Type", Value: literalText(mediaType)})
+	}
+	return nil
+}
+
+// httpRequest turns the prepared request into an http.Request, filling in credentials with lookup
+func (prepared *preparedRequest) httpRequest(ctx context.Context, lookup func(variable string) string) (*http.Request, error) {
+	var body []byte
+	contentType := ""
+	if strings.HasPrefix(prepared.ContentType, "multipart/") {
+		var b bytes.Buffer
+		writer := multipart.NewWriter(&b)
+		for _, field := range prepared.FormFields {
+			if err := writer.WriteField(field[0], field[1]); err != nil {
+				return nil, err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		body = b.Bytes()
+		contentType = writer.FormDataContentType()
+	} else if prepared.Body != nil {
+		body = prepared.Body
+	}
+
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, prepared.Method, prepared.URL.resolve(lookup), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for _, header := range prepared.Headers {
+		req.Header.Add(header.Name, header.Value.resolve(lookup))
+	}
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if len(prepared.BasicAuth) > 0 {
+		user, password, _ := strings.Cut(prepared.BasicAuth.resolve(lookup), ":")
+		req.SetBasicAuth(user, password)
+	}
+
+	return req, nil
+}
+
+// formValue renders a form field, encoding structured values as JSON
+func formValue(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return primitiveString(v)
+	}
+}
+
+// primitiveString renders a scalar the way it appears in a URL or header
+func primitiveString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func queryEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// reservedUnescaper undoes the escaping of reserved characters for allowReserved parameters
+var reservedUnescaper = strings.NewReplacer(
+	"%3A", ":", "%2F", "/", "%3F", "?", "%23", "#", "%5B", "[", "%5D", "]", "%40", "@",
+	"%21", "!", "%24", "$", "%26", "&", "%27", "'", "%28", "(", "%29", ")", "%2A", "*",
+	"%2B", "+", "%2C", ",", "%3B", ";", "%3D", "=",
+)
+
+// serializeParameter encodes a parameter value following its style and explode settings. Query
+// and cookie parameters come back as name=value pairs, path and header parameters as the value.
+func serializeParameter(param *openapi3.Parameter, value interface{}) (string, error) {
+	// Parameters described by content are sent as the encoded document
+	if param.Schema == nil && len(param.Content) > 0 {
+		if _, isText := value.(string); !isText {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return "", fmt.Errorf("failed to encode parameter %s: %w", param.Name, err)
+			}
+			value = string(data)
+		}
+	}
+
+	method, err := param.SerializationMethod()
+	if err != nil {
+		return "", err
+	}
+
+	escape := func(text string) string { return text }
+	switch param.In {
+	case openapi3.ParameterInPath:
+		escape = url.PathEscape
+	case openapi3.ParameterInQuery:
+		escape = queryEscape
+		if param.AllowReserved {
+			escape = func(text string) string { return reservedUnescaper.Replace(queryEscape(text)) }
+		}
+	}
+	name := param.Name
+	if param.In == openapi3.ParameterInQuery {
+		name = queryEscape(param.Name)
+	}
+
+	// Flatten the value into escaped items; objects become alternating keys and values
+	var items []string
+	var keys []string
+	isArray, isObject := false, false
+	switch v := value.(type) {
+	case []interface{}:
+		isArray = true
+		for _, item := range v {
+			items = append(items, escape(primitiveString(item)))
+		}
+	case map[string]interface{}:
+		isObject = true
+		keys = sortedKeys(v)
+		for _, key := range keys {
+			items = append(items, escape(key), escape(primitiveString(v[key])))
+		}
+	default:
+		items = []string{escape(primitiveString(v))}
+	}
+
+	// pairs joins object keys and values as key=value
+	pairs := func(separator string) string {
+		joined := make([]string, 0, len(items)/2)
+		for i := 0; i+1 < len(items); i += 2 {
+			joined = append(joined, items[i]+"="+items[i+1])
+		}
+		return strings.Join(joined, separator)
+	}
+
+	switch method.Style {
+	case openapi3.SerializationSimple:
+		if isObject && method.Explode {
+			return pairs(","), nil
+		}
+		return strings.Join(items, ","), nil
+
+	case openapi3.SerializationLabel:
+		switch {
+		case isObject && method.Explode:
+			return "." + pairs("."), nil
+		case isArray && method.Explode:
+			return "." + strings.Join(items, "."), nil
+		default:
+			return "." + strings.Join(items, ","), nil
+		}
+
+	case openapi3.SerializationMatrix:
+		switch {
+		case isObject && method.Explode:
+			return ";" + pairs(";"), nil
+		case isArray && method.Explode:
+			exploded := make([]string, 0, len(items))
+			for _, item := range items {
+				exploded = append(exploded, name+"="+item)
+			}
+			return ";" + strings.Join(exploded, ";"), nil
+		default:
+			return ";" + name + "=" + strings.Join(items, ","), nil
+		}
+
+	case openapi3.SerializationForm:
+		separator := "&"
+		if param.In == openapi3.ParameterInCookie {
+			separator = "; "
+		}
+		switch {
+		case isObject && method.Explode:
+			return pairs(separator), nil
+		case isArray && method.Explode:
+			exploded := make([]string, 0, len(items))
+			for _, item := range items {
+				exploded = append(exploded, name+"="+item)
+			}
+			return strings.Join(exploded, separator), nil
+		default:
+			return name + "=" + strings.Join(items, ","), nil
+		}
+
+	case openapi3.SerializationSpaceDelimited, openapi3.SerializationPipeDelimited:
+		delimiter := "%20"
+		if method.Style == openapi3.SerializationPipeDelimited {
+			delimiter = "|"
+		}
+		if isArray && method.Explode {
+			exploded := make([]string, 0, len(items))
+			for _, item := range items {
+				exploded = append(exploded, name+"="+item)
+			}
+			return strings.Join(exploded, "&"), nil
+		}
+		return name + "=" + strings.Join(items, delimiter), nil
+
+	case openapi3.SerializationDeepObject:
+		if !isObject {
+			return "", fmt.Errorf("parameter %s uses the deepObject style and needs an object value", param.Name)
+		}
+		nested := make([]string, 0, len(keys))
+		for i := 0; i+1 < len(items); i += 2 {
+			nested = append(nested, name+"["+items[i]+"]="+items[i+1])
+		}
+		return strings.Join(nested, "&"), nil
+	}
+
+	return "", fmt.Errorf("unsupported style %s for parameter %s", method.Style, param.Name)
+}
+
+// headerMap returns the headers with credentials as placeholders, joining repeated ones
+func (prepared *preparedRequest) headerMap() map[string]string {
+	headers := map[string]string{}
+	for _, header := range prepared.Headers {
+		if existing, exists := headers[header.Name]; exists {
+			headers[header.Name] = existing + "; " + header.Value.String()
+		} else {
+			headers[header.Name] = header.Value.String()
+		}
+	}
+	return headers
+}
+
+// curlCommand renders the request as a curl command
+func (prepared *preparedRequest) curlCommand() string {
+	command := []string{"curl"}
+	switch prepared.Method {
+	case "GET":
+	case "HEAD":
+		command = append(command, "--head")
+	default:
+		command = append(command, "-X", prepared.Method)
+	}
+	// Brackets and braces in the URL are not glob patterns
+	if strings.ContainsAny(prepared.URL.String(), "[]{}") {
+		command = append(command, "--globoff")
+	}
+	args := []string{strings.Join(append(command, prepared.URL.shellQuote()), " ")}
+
+	if len(prepared.BasicAuth) > 0 {
+		args = append(args, "-u "+prepared.BasicAuth.shellQuote())
+	}
+	for _, header := range prepared.Headers {
+		args = append(args, "-H "+append(literalText(header.Name+": "), header.Value...).shellQuote())
+	}
+
+	if strings.HasPrefix(prepared.ContentType, "multipart/") {
+		for _, field := range prepared.FormFields {
+			args = append(args, "-F "+literalText(field[0]+"="+field[1]).shellQuote())
+		}
+	} else if prepared.Body != nil {
+		args = append(args, "--data-raw "+literalText(string(prepared.Body)).shellQuote())
+	}
+
+	return strings.Join(args, " \\\n  ")
+}
+
+// httpieCommand renders the request as an HTTPie command
+func (prepared *preparedRequest) httpieCommand() string {
+	command := []string{"http"}
+	form := prepared.ContentType == "application/x-www-form-urlencoded"
+	multipart := strings.HasPrefix(prepared.ContentType, "multipart/")
+	if form {
+		command = append(command, "--form")
+	} else if multipart {
+		command = append(command, "--multipart")
+	}
+	args := []string{strings.Join(append(command, prepared.Method, prepared.URL.shellQuote()), " ")}
+
+	if len(prepared.BasicAuth) > 0 {
+		args = append(args, "-a "+prepared.BasicAuth.shellQuote())
+	}
+	for _, header := range prepared.Headers {
+		// HTTPie sets the content type of form requests itself
+		if (form || multipart) && header.Name == "Content-Type" {
+			continue
+		}
+		args = append(args, append(literalText(header.Name+":"), header.Value...).shellQuote())
+	}
+
+	if form || multipart {
+		for _, field := range prepared.FormFields {
+			// curl's -F name=@file uploads a file, which HTTPie spells name@file
+			if multipart && strings.HasPrefix(field[1], "@") {
+				args = append(args, literalText(field[0]+field[1]).shellQuote())
+				continue
+			}
+			args = append(args, literalText(field[0]+"="+field[1]).shellQuote())
+		}
+	} else if prepared.Body != nil {
+		args = append(args, "--raw "+literalText(string(prepared.Body)).shellQuote())
+	}
+
+	return strings.Join(args, " \\\n  ")
+}
+
+func (oas *OpenAPIServer) buildRequestHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	method, err := request.RequireString("method")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pathItem, operation, err := oas.findOperation(path, method)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	input, err := requestInputFromArguments(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	prepared, err := oas.buildRequest(path, method, pathItem, operation, input)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := map[string]interface{}{
+		"method":  prepared.Method,
+		"url":     prepared.URL.String(),
+		"headers": prepared.headerMap(),
+		"curl":    prepared.curlCommand(),
+		"httpie":  prepared.httpieCommand(),
+	}
+	if prepared.Body != nil {
+		result["body"] = string(prepared.Body)
+	} else if len(prepared.FormFields) > 0 {
+		fields := map[string]string{}
+		for _, field := range prepared.FormFields {
+			fields[field[0]] = field[1]
+		}
+		result["body"] = fields
+	}
+	if len(prepared.Credentials) > 0 {
+		result["credentials"] = prepared.Credentials
+	}
+	if len(prepared.Generated) > 0 {
+		result["generated"] = prepared.Generated
+	}
+	if len(prepared.Warnings) > 0 {
+		result["warnings"] = prepared.Warnings
+	}
+
+	return JSONResponse(result)
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildRequestSkipsEmptyParameters(t *testing.T) {
+	oas := loadTestSpec(t, `
+openapi: 3.0.3
+info: {title: params, version: "1"}
+servers: [{url: "https://api.example.com"}]
+paths:
+  /pets:
+    get:
+      parameters:
+        - {name: tags, in: query, schema: {type: array, items: {type: string}}}
+        - {name: ids, in: query, schema: {type: array, items: {type: integer}}}
+        - {name: filter, in: query, style: deepObject, explode: true, schema: {type: object}}
+        - {name: prefs, in: cookie, schema: {type: array, items: {type: string}}}
+      responses: {"200": {description: ok}}
+`)
+
+	tests := []struct {
+		parameters map[string]interface{}
+		url        string
+	}{
+		{map[string]interface{}{"tags": []interface{}{}}, "https://api.example.com/pets"},
+		{map[string]interface{}{"tags": []interface{}{}, "ids": []interface{}{1, 2}}, "https://api.example.com/pets?ids=1&ids=2"},
+		{map[string]interface{}{"ids": []interface{}{1}, "tags": []interface{}{}, "filter": map[string]interface{}{}}, "https://api.example.com/pets?ids=1"},
+		{map[string]interface{}{"tags": []interface{}{"a"}, "prefs": []interface{}{}}, "https://api.example.com/pets?tags=a"},
+	}
+	for _, test := range tests {
+		var result struct {
+			URL      string            `json:"url"`
+			Headers  map[string]string `json:"headers"`
+			Warnings []string          `json:"warnings"`
+		}
+		callToolJSON(t, oas.buildRequestHandler, map[string]interface{}{
+			"path": "/pets", "method": "get", "parameters": test.parameters,
+		}, &result)
+		if result.URL != test.url {
+			t.Errorf("parameters %v gave URL %s, want %s", test.parameters, result.URL, test.url)
+		}
+		if cookie, exists := result.Headers["Cookie"]; exists {
+			t.Errorf("parameters %v gave an empty Cookie header %q", test.parameters, cookie)
+		}
+		if !strings.Contains(strings.Join(result.Warnings, "\n"), "is empty and was left out") {
+			t.Errorf("parameters %v gave no warning about the empty parameter: %v", test.parameters, result.Warnings)
+		}
+	}
+}
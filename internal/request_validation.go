@@ -0,0 +1,252 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// violation is a way a request or response breaks its operation. Pointer is a JSON pointer into
+// the tool arguments holding the offending value, and Rule the schema keyword it breaks.
+type violation struct {
+	In        string      `json:"in"`
+	Parameter string      `json:"parameter,omitempty"`
+	Pointer   string      `json:"pointer"`
+	Rule      string      `json:"rule,omitempty"`
+	Expected  interface{} `json:"expected,omitempty"`
+	Message   string      `json:"message"`
+}
+
+// schemaViolations flattens schema errors, which come back nested when every error is collected
+func schemaViolations(err error, base violation) []violation {
+	// errors.As would look through a MultiError and stop at its first error
+	if multi, ok := err.(openapi3.MultiError); ok {
+		violations := []violation{}
+		for _, inner := range multi {
+			violations = append(violations, schemaViolations(inner, base)...)
+		}
+		return violations
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		v := base
+		for _, token := range schemaErr.JSONPointer() {
+			v.Pointer += "/" + escapePointer(token)
+		}
+		// allOf only wraps the error of the part that failed, which is more useful on its own
+		if schemaErr.SchemaField == "allOf" && schemaErr.Origin != nil {
+			return schemaViolations(schemaErr.Origin, v)
+		}
+		// So does oneOf when a discriminator picked the one member to check. The member's errors
+		// already carry the full path.
+		var members openapi3.MultiError
+		if schemaErr.SchemaField == "oneOf" && errors.As(schemaErr.Origin, &members) && len(members) == 1 {
+			return schemaViolations(members[0], base)
+		}
+		v.Rule = schemaErr.SchemaField
+		v.Expected = schemaKeyword(schemaErr.Schema, schemaErr.SchemaField)
+		v.Message = schemaErr.Reason
+		return []violation{v}
+	}
+
+	var parseErr *openapi3filter.ParseError
+	if errors.As(err, &parseErr) {
+		v := base
+		for _, token := range parseErr.Path() {
+			v.Pointer += "/" + escapePointer(fmt.Sprint(token))
+		}
+		v.Rule = "style"
+		v.Message = parseErr.Error()
+		return []violation{v}
+	}
+
+	v := base
+	v.Message = err.Error()
+	return []violation{v}
+}
+
+// schemaKeyword returns the value of a keyword as written in the spec. Schema.JSONLookup uses the
+// names of the Go fields instead, such as max for maximum.
+func schemaKeyword(schema *openapi3.Schema, keyword string) interface{} {
+	if schema == nil || keyword == "" {
+		return nil
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields[keyword]
+}
+
+// requestViolations maps the errors of openapi3filter.ValidateRequest to violations
+func requestViolations(err error, input requestInput) []violation {
+	if multi, ok := err.(openapi3.MultiError); ok {
+		violations := []violation{}
+		for _, inner := range multi {
+			violations = append(violations, requestViolations(inner, input)...)
+		}
+		return violations
+	}
+
+	var securityErr *openapi3filter.SecurityRequirementsError
+	if errors.As(err, &securityErr) {
+		violations := []violation{}
+		for _, inner := range securityErr.Errors {
+			violations = append(violations, violation{In: "security", Rule: "security", Message: inner.Error()})
+		}
+		return violations
+	}
+
+	requestErr, ok := err.(*openapi3filter.RequestError)
+	if !ok {
+		return []violation{{In: "request", Message: err.Error()}}
+	}
+
+	base := violation{In: "body", Pointer: "/body"}
+	if param := requestErr.Parameter; param != nil {
+		base = violation{In: param.In, Parameter: param.Name, Pointer: "/parameters/" + escapePointer(param.Name)}
+		if _, provided := input.Parameters[param.Name]; !provided && param.In == openapi3.ParameterInHeader {
+			for name := range input.Headers {
+				if strings.EqualFold(name, param.Name) {
+					base.Pointer = "/headers/" + escapePointer(name)
+				}
+			}
+		}
+	}
+
+	switch {
+	case errors.Is(requestErr.Err, openapi3filter.ErrInvalidRequired):
+		base.Rule = "required"
+		base.Message = requestErr.Err.Error()
+		return []violation{base}
+	case errors.Is(requestErr.Err, openapi3filter.ErrInvalidEmptyValue):
+		base.Rule = "allowEmptyValue"
+		base.Message = requestErr.Err.Error()
+		return []violation{base}
+	case requestErr.Err == nil:
+		if requestErr.RequestBody != nil {
+			base.Pointer = "/media_type"
+			base.Rule = "content"
+		}
+		base.Message = requestErr.Reason
+		return []violation{base}
+	}
+	return schemaViolations(requestErr.Err, base)
+}
+
+// credentialsPresent stands in for authentication: a scheme is satisfied when its credentials are
+// sent in the right place, whatever their value
+func credentialsPresent(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+	req := input.RequestValidationInput.Request
+	scheme := input.SecurityScheme
+
+	switch scheme.Type {
+	case "http":
+		prefix := scheme.Scheme + " "
+		if !strings.HasPrefix(strings.ToLower(req.Header.Get("Authorization")), strings.ToLower(prefix)) {
+			return fmt.Errorf("security scheme %s needs an Authorization header with %s credentials", input.SecuritySchemeName, scheme.Scheme)
+		}
+	case "apiKey":
+		found := false
+		switch scheme.In {
+		case openapi3.ParameterInHeader:
+			found = req.Header.Get(scheme.Name) != ""
+		case openapi3.ParameterInQuery:
+			found = req.URL.Query().Has(scheme.Name)
+		case openapi3.ParameterInCookie:
+			_, err := req.Cookie(scheme.Name)
+			found = err == nil
+		}
+		if !found {
+			return fmt.Errorf("security scheme %s needs the API key in the %s %s", input.SecuritySchemeName, scheme.In, scheme.Name)
+		}
+	case "oauth2", "openIdConnect":
+		if !strings.HasPrefix(strings.ToLower(req.Header.Get("Authorization")), "bearer ") {
+			return fmt.Errorf("security scheme %s needs an Authorization header with a bearer token", input.SecuritySchemeName)
+		}
+	}
+	return nil
+}
+
+// validateRequest checks a request against its operation and returns every violation
+func (oas *OpenAPIServer) validateRequest(ctx context.Context, path string, pathItem *openapi3.PathItem, operation *openapi3.Operation, req *http.Request, pathParams map[string]string, input requestInput) []violation {
+	err := openapi3filter.ValidateRequest(ctx, &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route: &routers.Route{
+			Spec:      oas.spec,
+			Path:      path,
+			PathItem:  pathItem,
+			Method:    req.Method,
+			Operation: operation,
+		},
+		Options: &openapi3filter.Options{
+			MultiError:          true,
+			SkipSettingDefaults: true,
+			AuthenticationFunc:  credentialsPresent,
+		},
+	})
+	if err == nil {
+		return []violation{}
+	}
+	return requestViolations(err, input)
+}
+
+func (oas *OpenAPIServer) validateRequestHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	method, err := request.RequireString("method")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pathItem, operation, err := oas.findOperation(path, method)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	input, err := requestInputFromArguments(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	input.NoExamples = true
+	input.NoCredentials = true
+
+	prepared, err := oas.buildRequest(path, method, pathItem, operation, input)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	req, err := prepared.httpRequest(ctx, func(string) string { return "" })
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	violations := oas.validateRequest(ctx, path, pathItem, operation, req, prepared.PathParams, input)
+	result := map[string]interface{}{
+		"valid":      len(violations) == 0,
+		"operation":  prepared.Method + " " + path,
+		"violations": violations,
+	}
+	if len(prepared.Warnings) > 0 {
+		result["warnings"] = prepared.Warnings
+	}
+
+	return JSONResponse(result)
+}
@@ -0,0 +1,105 @@
+package internal
+
+import "testing"
+
+const requestValidationSpec = `
+openapi: 3.0.3
+info: {title: requests, version: "1"}
+paths:
+  /users:
+    post:
+      parameters:
+        - {name: notify, in: query, required: true, schema: {type: boolean}}
+        - {name: X-Request-Id, in: header, required: true, schema: {type: string}}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name: {type: string}
+                age: {type: integer, minimum: 0}
+      responses: {"201": {description: created}}
+`
+
+type requestValidation struct {
+	Valid      bool        `json:"valid"`
+	Violations []violation `json:"violations"`
+}
+
+func validateTestRequest(t *testing.T, oas *OpenAPIServer, args map[string]interface{}) requestValidation {
+	t.Helper()
+	args["path"] = "/users"
+	args["method"] = "post"
+	var result requestValidation
+	callToolJSON(t, oas.validateRequestHandler, args, &result)
+	return result
+}
+
+func TestValidateRequestMissingParameters(t *testing.T) {
+	oas := loadTestSpec(t, requestValidationSpec)
+
+	result := validateTestRequest(t, oas, map[string]interface{}{
+		"body": map[string]interface{}{"name": "Ada"},
+	})
+	if result.Valid {
+		t.Fatal("a request without its required parameters is valid")
+	}
+	missing := map[string]violation{}
+	for _, v := range result.Violations {
+		missing[v.Parameter] = v
+	}
+	for _, want := range []violation{
+		{In: "query", Parameter: "notify", Pointer: "/parameters/notify", Rule: "required"},
+		{In: "header", Parameter: "X-Request-Id", Pointer: "/parameters/X-Request-Id", Rule: "required"},
+	} {
+		got, found := missing[want.Parameter]
+		if !found {
+			t.Errorf("no violation for %s in %v", want.Parameter, result.Violations)
+			continue
+		}
+		if got.In != want.In || got.Pointer != want.Pointer || got.Rule != want.Rule {
+			t.Errorf("%s: got %+v, want in=%s pointer=%s rule=%s", want.Parameter, got, want.In, want.Pointer, want.Rule)
+		}
+	}
+}
+
+func TestValidateRequestBodyType(t *testing.T) {
+	oas := loadTestSpec(t, requestValidationSpec)
+
+	result := validateTestRequest(t, oas, map[string]interface{}{
+		"parameters": map[string]interface{}{"notify": true, "X-Request-Id": "1"},
+		"body":       map[string]interface{}{"name": "Ada", "age": "ten"},
+	})
+	if result.Valid || len(result.Violations) != 1 {
+		t.Fatalf("want one violation for the age, got %+v", result.Violations)
+	}
+	got := result.Violations[0]
+	if got.In != "body" || got.Pointer != "/body/age" || got.Rule != "type" || got.Expected != "integer" {
+		t.Errorf("got %+v, want the type rule broken at /body/age", got)
+	}
+
+	// A raw header supplies the header parameter as well
+	result = validateTestRequest(t, oas, map[string]interface{}{
+		"parameters": map[string]interface{}{"notify": true},
+		"headers":    map[string]interface{}{"X-Request-Id": "1"},
+		"body":       map[string]interface{}{"name": "Ada", "age": -1},
+	})
+	if len(result.Violations) != 1 || result.Violations[0].Pointer != "/body/age" || result.Violations[0].Rule != "minimum" {
+		t.Errorf("want the minimum rule broken at /body/age, got %+v", result.Violations)
+	}
+}
+
+func TestValidateRequestValid(t *testing.T) {
+	oas := loadTestSpec(t, requestValidationSpec)
+
+	result := validateTestRequest(t, oas, map[string]interface{}{
+		"parameters": map[string]interface{}{"notify": false, "X-Request-Id": "1"},
+		"body":       map[string]interface{}{"name": "Ada", "age": 36},
+	})
+	if !result.Valid || len(result.Violations) != 0 {
+		t.Fatalf("a valid request was rejected: %+v", result.Violations)
+	}
+}
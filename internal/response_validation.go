@@ -0,0 +1,267 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// responseCheck is the outcome of checking a response against its operation
+type responseCheck struct {
+	// Response is the documented response that applies: the status code, its range or default
+	Response   string
+	Violations []violation
+	// Undocumented lists pointers to body fields the schema does not declare
+	Undocumented []string
+}
+
+// matchResponse finds the documented response for a status code the way clients do: the exact
+// code, then its range (e.g. 4XX), then default
+func matchResponse(operation *openapi3.Operation, status int) (string, *openapi3.Response) {
+	if operation.Responses == nil {
+		return "", nil
+	}
+	code := strconv.Itoa(status)
+	candidates := []string{code}
+	if len(code) == 3 {
+		candidates = append(candidates, code[:1]+"XX")
+	}
+	candidates = append(candidates, "default")
+	for _, candidate := range candidates {
+		if responseRef := operation.Responses.Value(candidate); responseRef != nil && responseRef.Value != nil {
+			return candidate, responseRef.Value
+		}
+	}
+	return "", nil
+}
+
+// validateResponse checks a response against its operation in spec and returns every violation
+func validateResponse(ctx context.Context, spec *openapi3.T, path string, pathItem *openapi3.PathItem, operation *openapi3.Operation, req *http.Request, status int, header http.Header, body []byte) responseCheck {
+	check := responseCheck{Violations: []violation{}, Undocumented: []string{}}
+
+	key, response := matchResponse(operation, status)
+	if response == nil {
+		documented := []string{}
+		if operation.Responses != nil {
+			documented = sortedKeys(operation.Responses.Map())
+		}
+		check.Violations = append(check.Violations, violation{
+			In:      "status",
+			Pointer: "/status",
+			Rule:    "responses",
+			Message: fmt.Sprintf("status %d is not documented. Documented: %s", status, strings.Join(documented, ", ")),
+		})
+		return check
+	}
+	check.Response = key
+
+	if len(response.Content) == 0 && len(body) > 0 {
+		check.Violations = append(check.Violations, violation{In: "body", Pointer: "/body", Rule: "content", Message: "the response documents no body"})
+	}
+
+	// openapi3filter stops at the first header error and skips the body after it, so every header
+	// and the body are checked against a copy of the response holding only that part
+	run := func(part *openapi3.Response, excludeBody bool, base violation) {
+		copied := *operation
+		copied.Responses = openapi3.NewResponses(openapi3.WithName(key, part))
+		input := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: &openapi3filter.RequestValidationInput{
+				Request: req,
+				Route: &routers.Route{
+					Spec:      spec,
+					Path:      path,
+					PathItem:  pathItem,
+					Method:    req.Method,
+					Operation: &copied,
+				},
+			},
+			Status: status,
+			Header: header,
+			Options: &openapi3filter.Options{
+				MultiError:          true,
+				ExcludeResponseBody: excludeBody,
+			},
+		}
+		input.SetBodyBytes(body)
+
+		err := openapi3filter.ValidateResponse(ctx, input)
+		if err == nil {
+			return
+		}
+		responseErr, ok := err.(*openapi3filter.ResponseError)
+		if !ok || responseErr.Err == nil {
+			v := base
+			v.Message = err.Error()
+			if ok && base.In == "body" {
+				// Without a cause the body was sent with a content type the response does not document
+				v.Pointer, v.Rule, v.Message = "/content_type", "content", responseErr.Reason
+			} else if ok && base.In == "header" {
+				v.Rule, v.Message = "required", responseErr.Reason
+			}
+			check.Violations = append(check.Violations, v)
+			return
+		}
+		check.Violations = append(check.Violations, schemaViolations(responseErr.Err, base)...)
+	}
+
+	for _, name := range sortedKeys(response.Headers) {
+		if strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		part := *response
+		part.Headers = openapi3.Headers{name: response.Headers[name]}
+		part.Content = nil
+		run(&part, true, violation{In: "header", Parameter: name, Pointer: "/headers/" + escapePointer(name)})
+	}
+
+	if len(response.Content) > 0 && len(body) > 0 {
+		part := *response
+		part.Headers = nil
+		run(&part, false, violation{In: "body", Pointer: "/body"})
+
+		mediaType := header.Get("Content-Type")
+		if media := response.Content.Get(mediaType); media != nil && media.Schema != nil && isJSONMediaType(mediaType) {
+			var value interface{}
+			if json.Unmarshal(body, &value) == nil {
+				collectUndocumented(media.Schema, value, "/body", &check.Undocumented)
+			}
+		}
+	}
+
+	return check
+}
+
+func isJSONMediaType(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// collectUndocumented walks a value alongside its schema and records fields the schema does not
+// declare. Objects without declared properties are free-form and accept any field.
+func collectUndocumented(schemaRef *openapi3.SchemaRef, value interface{}, pointer string, found *[]string) {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return
+	}
+	schema := mergeAllOf(schemaRef).Value
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// Fields of any oneOf/anyOf member count as declared
+		declared := openapi3.Schemas{}
+		for name, prop := range schema.Properties {
+			declared[name] = prop
+		}
+		for _, member := range append(append(openapi3.SchemaRefs{}, schema.OneOf...), schema.AnyOf...) {
+			if member == nil || member.Value == nil {
+				continue
+			}
+			for name, prop := range mergeAllOf(member).Value.Properties {
+				if _, exists := declared[name]; !exists {
+					declared[name] = prop
+				}
+			}
+		}
+
+		for _, name := range sortedKeys(v) {
+			fieldPointer := pointer + "/" + escapePointer(name)
+			switch {
+			case declared[name] != nil:
+				collectUndocumented(declared[name], v[name], fieldPointer, found)
+			case schema.AdditionalProperties.Schema != nil:
+				collectUndocumented(schema.AdditionalProperties.Schema, v[name], fieldPointer, found)
+			case schema.AdditionalProperties.Has != nil:
+				// Allowed explicitly, or rejected by the schema validation already
+			case len(declared) > 0:
+				*found = append(*found, fieldPointer)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			collectUndocumented(schema.Items, item, pointer+"/"+strconv.Itoa(i), found)
+		}
+	}
+}
+
+func (oas *OpenAPIServer) validateResponseHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	method, err := request.RequireString("method")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	status, err := request.RequireInt("status")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pathItem, operation, err := oas.findOperation(path, method)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	header := http.Header{}
+	if raw, exists := request.GetArguments()["headers"]; exists && raw != nil {
+		values, ok := raw.(map[string]interface{})
+		if !ok {
+			return mcp.NewToolResultError("headers must be an object"), nil
+		}
+		for _, name := range sortedKeys(values) {
+			header.Add(name, primitiveString(values[name]))
+		}
+	}
+
+	var body []byte
+	switch value := request.GetArguments()["body"].(type) {
+	case nil:
+	case string:
+		body = []byte(value)
+	default:
+		if body, err = json.Marshal(value); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to encode body: %v", err)), nil
+		}
+	}
+
+	// The content type defaults to the one the documented response would use
+	if contentType := request.GetString("content_type", ""); contentType != "" {
+		header.Set("Content-Type", contentType)
+	} else if header.Get("Content-Type") == "" && body != nil {
+		if _, response := matchResponse(operation, status); response != nil && len(response.Content) > 0 {
+			mediaType, _, _ := selectMediaType(response.Content, "")
+			header.Set("Content-Type", mediaType)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), DefaultServerURL+path, http.NoBody)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	check := validateResponse(ctx, oas.spec, path, pathItem, operation, req, status, header, body)
+	result := map[string]interface{}{
+		"valid":      len(check.Violations) == 0,
+		"operation":  req.Method + " " + path,
+		"status":     status,
+		"violations": check.Violations,
+	}
+	if check.Response != "" {
+		result["response"] = check.Response
+	}
+	if len(check.Undocumented) > 0 {
+		result["undocumented_fields"] = check.Undocumented
+	}
+
+	return JSONResponse(result)
+}
@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+const responseValidationSpec = `
+openapi: 3.0.3
+info: {title: responses, version: "1"}
+paths:
+  /users/{id}:
+    get:
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id, name]
+                properties:
+                  id: {type: string}
+                  name: {type: string}
+                  address:
+                    type: object
+                    properties:
+                      city: {type: string}
+        "2XX":
+          description: other success
+          content:
+            application/json:
+              schema: {type: object, properties: {queued: {type: boolean}}}
+        default:
+          description: error
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [message]
+                properties:
+                  message: {type: string}
+  /health:
+    get:
+      responses:
+        "204": {description: healthy}
+`
+
+type responseValidation struct {
+	Valid        bool        `json:"valid"`
+	Response     string      `json:"response"`
+	Violations   []violation `json:"violations"`
+	Undocumented []string    `json:"undocumented_fields"`
+}
+
+func validateTestResponse(t *testing.T, oas *OpenAPIServer, args map[string]interface{}) responseValidation {
+	t.Helper()
+	if _, exists := args["path"]; !exists {
+		args["path"] = "/users/{id}"
+	}
+	args["method"] = "get"
+	var result responseValidation
+	callToolJSON(t, oas.validateResponseHandler, args, &result)
+	return result
+}
+
+func TestValidateResponseUndocumentedStatus(t *testing.T) {
+	oas := loadTestSpec(t, responseValidationSpec)
+
+	result := validateTestResponse(t, oas, map[string]interface{}{"path": "/health", "status": float64(500)})
+	if result.Valid || len(result.Violations) != 1 {
+		t.Fatalf("want one violation for the status, got %+v", result.Violations)
+	}
+	if got := result.Violations[0]; got.Pointer != "/status" || got.Rule != "responses" {
+		t.Errorf("got %+v, want the responses rule broken at /status", got)
+	}
+}
+
+func TestValidateResponseMatchesRangeAndDefault(t *testing.T) {
+	oas := loadTestSpec(t, responseValidationSpec)
+
+	tests := []struct {
+		name     string
+		status   int
+		body     map[string]interface{}
+		response string
+		valid    bool
+	}{
+		{"exact code", 200, map[string]interface{}{"id": "1", "name": "Ada"}, "200", true},
+		{"range", 202, map[string]interface{}{"queued": true}, "2XX", true},
+		{"range checked against its own schema", 202, map[string]interface{}{"queued": "yes"}, "2XX", false},
+		{"default", 404, map[string]interface{}{"message": "not found"}, "default", true},
+		{"default checked against its own schema", 503, map[string]interface{}{"id": "1"}, "default", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validateTestResponse(t, oas, map[string]interface{}{"status": float64(tt.status), "body": tt.body})
+			if result.Response != tt.response {
+				t.Errorf("response = %q, want %q", result.Response, tt.response)
+			}
+			if result.Valid != tt.valid {
+				t.Errorf("valid = %v, want %v: %+v", result.Valid, tt.valid, result.Violations)
+			}
+		})
+	}
+}
+
+func TestValidateResponseFields(t *testing.T) {
+	oas := loadTestSpec(t, responseValidationSpec)
+
+	result := validateTestResponse(t, oas, map[string]interface{}{
+		"status": float64(200),
+		"body": map[string]interface{}{
+			"id":      "1",
+			"nick":    "ada",
+			"address": map[string]interface{}{"city": "London", "zip": "N1"},
+		},
+	})
+	if result.Valid || len(result.Violations) != 1 {
+		t.Fatalf("want one violation for the missing name, got %+v", result.Violations)
+	}
+	if got := result.Violations[0]; got.Pointer != "/body/name" || got.Rule != "required" {
+		t.Errorf("got %+v, want the required rule broken at /body/name", got)
+	}
+	if want := []string{"/body/address/zip", "/body/nick"}; !reflect.DeepEqual(result.Undocumented, want) {
+		t.Errorf("undocumented = %v, want %v", result.Undocumented, want)
+	}
+}
+
+func TestValidateResponseContentType(t *testing.T) {
+	oas := loadTestSpec(t, responseValidationSpec)
+
+	result := validateTestResponse(t, oas, map[string]interface{}{
+		"status":       float64(200),
+		"content_type": "text/html",
+		"body":         "<html></html>",
+	})
+	if result.Valid || len(result.Violations) != 1 {
+		t.Fatalf("want one violation for the content type, got %+v", result.Violations)
+	}
+	if got := result.Violations[0]; got.Pointer != "/content_type" || got.Rule != "content" {
+		t.Errorf("got %+v, want the content rule broken at /content_type", got)
+	}
+}
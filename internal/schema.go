@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// mergeAllOf flattens the allOf chain of a schema into a single inline schema.
+// Fields declared directly on the schema win over those inherited from allOf members, while
+// validation bounds and enums are intersected so the merged view is as strict as all parts together.
+func mergeAllOf(schemaRef *openapi3.SchemaRef) *openapi3.SchemaRef {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return schemaRef
+	}
+
+	merged := &openapi3.Schema{}
+	mergeSchemaInto(merged, schemaRef.Value, map[*openapi3.Schema]bool{}, false)
+
+	return &openapi3.SchemaRef{Value: merged}
+}
+
+// minBound returns the lower of two optional upper bounds
+func minBound(a, b *uint64) *uint64 {
+	if a == nil || (b != nil && *b < *a) {
+		return b
+	}
+	return a
+}
+
+func mergeSchemaInto(dst, src *openapi3.Schema, visited map[*openapi3.Schema]bool, inherited bool) {
+	if src == nil || visited[src] {
+		return
+	}
+	visited[src] = true
+
+	if dst.Type == nil && src.Type != nil {
+		dst.Type = src.Type
+	}
+	if dst.Title == "" {
+		dst.Title = src.Title
+	}
+	if dst.Format == "" {
+		dst.Format = src.Format
+	}
+	if dst.Description == "" {
+		dst.Description = src.Description
+	}
+	if dst.Default == nil {
+		dst.Default = src.Default
+	}
+	if dst.Example == nil {
+		dst.Example = src.Example
+	}
+	dst.Nullable = dst.Nullable || src.Nullable
+	dst.ReadOnly = dst.ReadOnly || src.ReadOnly
+	dst.WriteOnly = dst.WriteOnly || src.WriteOnly
+	dst.Deprecated = dst.Deprecated || src.Deprecated
+	dst.UniqueItems = dst.UniqueItems || src.UniqueItems
+	if src.Min != nil && (dst.Min == nil || *src.Min > *dst.Min) {
+		dst.Min, dst.ExclusiveMin = src.Min, src.ExclusiveMin
+	} else if src.Min != nil && *src.Min == *dst.Min {
+		dst.ExclusiveMin = dst.ExclusiveMin || src.ExclusiveMin
+	}
+	if src.Max != nil && (dst.Max == nil || *src.Max < *dst.Max) {
+		dst.Max, dst.ExclusiveMax = src.Max, src.ExclusiveMax
+	} else if src.Max != nil && *src.Max == *dst.Max {
+		dst.ExclusiveMax = dst.ExclusiveMax || src.ExclusiveMax
+	}
+	// Only a multipleOf that is a multiple of the other covers both
+	if src.MultipleOf != nil && (dst.MultipleOf == nil || (*src.MultipleOf > *dst.MultipleOf && math.Mod(*src.MultipleOf, *dst.MultipleOf) == 0)) {
+		dst.MultipleOf = src.MultipleOf
+	}
+	dst.MinLength = max(dst.MinLength, src.MinLength)
+	dst.MaxLength = minBound(dst.MaxLength, src.MaxLength)
+	if dst.Pattern == "" {
+		dst.Pattern = src.Pattern
+	}
+	dst.MinItems = max(dst.MinItems, src.MinItems)
+	dst.MaxItems = minBound(dst.MaxItems, src.MaxItems)
+	dst.MinProps = max(dst.MinProps, src.MinProps)
+	dst.MaxProps = minBound(dst.MaxProps, src.MaxProps)
+	if len(dst.Enum) == 0 {
+		dst.Enum = src.Enum
+	} else if len(src.Enum) > 0 {
+		// Only values allowed by both enums remain
+		common := []interface{}{}
+		for _, value := range dst.Enum {
+			for _, other := range src.Enum {
+				if reflect.DeepEqual(value, other) {
+					common = append(common, value)
+					break
+				}
+			}
+		}
+		dst.Enum = common
+	}
+	if dst.Items == nil {
+		dst.Items = src.Items
+	}
+	if dst.Not == nil {
+		dst.Not = src.Not
+	}
+	if dst.AdditionalProperties.Has == nil && dst.AdditionalProperties.Schema == nil {
+		dst.AdditionalProperties = src.AdditionalProperties
+	}
+
+	for _, name := range src.Required {
+		if !slices.Contains(dst.Required, name) {
+			dst.Required = append(dst.Required, name)
+		}
+	}
+
+	for name, prop := range src.Properties {
+		if dst.Properties == nil {
+			dst.Properties = openapi3.Schemas{}
+		}
+		if _, exists := dst.Properties[name]; !exists {
+			dst.Properties[name] = prop
+		}
+	}
+
+	// A polymorphic parent lists its own subtypes, which must not leak into a subtype extending it
+	if !inherited || src.Discriminator == nil {
+		if dst.Discriminator == nil {
+			dst.Discriminator = src.Discriminator
+		}
+		dst.OneOf = append(dst.OneOf, src.OneOf...)
+		dst.AnyOf = append(dst.AnyOf, src.AnyOf...)
+	}
+
+	for _, member := range src.AllOf {
+		if member != nil {
+			mergeSchemaInto(dst, member.Value, visited, true)
+		}
+	}
+}
+
+// discriminatorVariant is a concrete subtype selectable through a discriminator
+type discriminatorVariant struct {
+	Value  string
+	Ref    string
+	Schema *openapi3.SchemaRef
+}
+
+// discriminatorVariants lists the concrete subtypes of a polymorphic schema. Explicit mapping
+// entries come first, followed by oneOf/anyOf members and, for discriminated base schemas, schemas
+// extending this one via allOf. Implicit entries use their component name as the discriminator value.
+func (oas *OpenAPIServer) discriminatorVariants(schemaName string, schema *openapi3.Schema) []discriminatorVariant {
+	variants := []discriminatorVariant{}
+	seen := map[string]bool{}
+
+	add := func(value, ref string) {
+		// Mapping values may be bare schema names, anything else is a reference
+		if !strings.ContainsAny(ref, "/.#") {
+			ref = "#/components/schemas/" + ref
+		}
+		if seen[ref] {
+			return
+		}
+		seen[ref] = true
+		variants = append(variants, discriminatorVariant{
+			Value:  value,
+			Ref:    ref,
+			Schema: oas.variantSchema(ref),
+		})
+	}
+
+	if schema.Discriminator != nil {
+		values := make([]string, 0, len(schema.Discriminator.Mapping))
+		for value := range schema.Discriminator.Mapping {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			add(value, schema.Discriminator.Mapping[value])
+		}
+	}
+
+	for _, member := range append(append(openapi3.SchemaRefs{}, schema.OneOf...), schema.AnyOf...) {
+		if member != nil && member.Ref != "" {
+			add(refName(member.Ref), member.Ref)
+		}
+	}
+
+	// Subtypes declared as allOf extensions of a discriminated base schema
+	if schema.Discriminator != nil && oas.spec.Components != nil && schemaName != "" {
+		baseRef := "#/components/schemas/" + schemaName
+		names := make([]string, 0, len(oas.spec.Components.Schemas))
+		for name := range oas.spec.Components.Schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			candidate := oas.spec.Components.Schemas[name]
+			if candidate == nil || candidate.Value == nil {
+				continue
+			}
+			for _, member := range candidate.Value.AllOf {
+				if member != nil && member.Ref == baseRef {
+					add(name, "#/components/schemas/"+name)
+					break
+				}
+			}
+		}
+	}
+
+	return variants
+}
+
+// variantSchema resolves the reference of a subtype, which may point into another document
+func (oas *OpenAPIServer) variantSchema(ref string) *openapi3.SchemaRef {
+	if strings.HasPrefix(ref, "#/components/schemas/") {
+		return oas.componentSchema(ref)
+	}
+	value, _, err := oas.resolveRef(ref)
+	if schema, isSchema := value.(*openapi3.Schema); err == nil && isSchema {
+		return &openapi3.SchemaRef{Ref: ref, Value: schema}
+	}
+	return nil
+}
+
+// componentSchema looks up a schema by its #/components/schemas/ reference
+func (oas *OpenAPIServer) componentSchema(ref string) *openapi3.SchemaRef {
+	if oas.spec.Components == nil || !strings.HasPrefix(ref, "#/components/schemas/") {
+		return nil
+	}
+	return oas.spec.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+}
+
+// refName returns the last segment of a reference
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
@@ -0,0 +1,191 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestMergeAllOfTakesStricterBounds(t *testing.T) {
+	loader := openapi3.NewLoader()
+	spec, err := loader.LoadFromData([]byte(`
+openapi: 3.0.3
+info: {title: merge, version: "1"}
+paths: {}
+components:
+  schemas:
+    Base:
+      type: object
+      minProperties: 1
+      maxProperties: 10
+      properties:
+        amount: {type: number}
+    Amount:
+      allOf:
+        - $ref: "#/components/schemas/Base"
+        - minProperties: 2
+          maxProperties: 20
+      minProperties: 0
+      maxProperties: 5
+    Number:
+      minimum: 0
+      maximum: 100
+      exclusiveMaximum: true
+      multipleOf: 2
+      allOf:
+        - minimum: 10
+          maximum: 200
+          multipleOf: 4
+        - maximum: 100
+          minimum: 10
+          exclusiveMinimum: true
+    Text:
+      minLength: 1
+      maxLength: 50
+      enum: [a, bb, ccc]
+      allOf:
+        - minLength: 2
+          maxLength: 100
+          enum: [bb, ccc, dddd]
+    List:
+      minItems: 3
+      allOf:
+        - minItems: 1
+          maxItems: 8
+        - maxItems: 4
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := func(name string) *openapi3.Schema {
+		return mergeAllOf(spec.Components.Schemas[name]).Value
+	}
+
+	amount := merged("Amount")
+	if amount.MinProps != 2 || amount.MaxProps == nil || *amount.MaxProps != 5 {
+		t.Errorf("properties bounds are %d..%v, want 2..5", amount.MinProps, amount.MaxProps)
+	}
+
+	number := merged("Number")
+	if *number.Min != 10 || !number.ExclusiveMin {
+		t.Errorf("minimum is %v exclusive=%v, want exclusive 10", *number.Min, number.ExclusiveMin)
+	}
+	if *number.Max != 100 || !number.ExclusiveMax {
+		t.Errorf("maximum is %v exclusive=%v, want exclusive 100", *number.Max, number.ExclusiveMax)
+	}
+	if *number.MultipleOf != 4 {
+		t.Errorf("multipleOf is %v, want 4", *number.MultipleOf)
+	}
+
+	text := merged("Text")
+	if text.MinLength != 2 || text.MaxLength == nil || *text.MaxLength != 50 {
+		t.Errorf("length bounds are %d..%v, want 2..50", text.MinLength, text.MaxLength)
+	}
+	if want := []interface{}{"bb", "ccc"}; !reflect.DeepEqual(text.Enum, want) {
+		t.Errorf("enum is %v, want %v", text.Enum, want)
+	}
+
+	list := merged("List")
+	if list.MinItems != 3 || list.MaxItems == nil || *list.MaxItems != 4 {
+		t.Errorf("item bounds are %d..%v, want 3..4", list.MinItems, list.MaxItems)
+	}
+}
+
+func TestSchemaVariants(t *testing.T) {
+	oas := loadTestSpecFiles(t, map[string]string{
+		"openapi.yaml": `
+openapi: 3.0.3
+info: {title: variants, version: "1"}
+paths: {}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [petType]
+      properties:
+        petType: {type: string}
+      discriminator:
+        propertyName: petType
+        mapping:
+          kitty: Cat
+          lizard: ./reptiles.yaml#/Lizard
+      oneOf:
+        - $ref: "#/components/schemas/Cat"
+        - $ref: "#/components/schemas/Dog"
+        - $ref: ./reptiles.yaml#/Lizard
+    Cat:
+      type: object
+      properties:
+        lives: {type: integer}
+    Dog:
+      type: object
+      properties:
+        barks: {type: boolean}
+    Hamster:
+      allOf:
+        - $ref: "#/components/schemas/Pet"
+        - type: object
+          properties:
+            wheel: {type: boolean}
+`,
+		"reptiles.yaml": `
+Lizard:
+  type: object
+  properties:
+    scales: {type: integer}
+`,
+	})
+
+	var result struct {
+		Polymorphism struct {
+			PropertyName string `json:"propertyName"`
+			Variants     []struct {
+				Value string `json:"value"`
+				Ref   string `json:"ref"`
+			} `json:"variants"`
+		} `json:"polymorphism"`
+	}
+	callToolJSON(t, oas.showSchemaHandler, map[string]interface{}{"ref": "#/components/schemas/Pet"}, &result)
+	got := []string{}
+	for _, variant := range result.Polymorphism.Variants {
+		got = append(got, variant.Value+"="+variant.Ref)
+	}
+	// Explicit mappings first, then the remaining oneOf members, then allOf extensions
+	want := []string{
+		"kitty=#/components/schemas/Cat",
+		"lizard=./reptiles.yaml#/Lizard",
+		"Dog=#/components/schemas/Dog",
+		"Hamster=#/components/schemas/Hamster",
+	}
+	if result.Polymorphism.PropertyName != "petType" || !reflect.DeepEqual(got, want) {
+		t.Fatalf("variants of %s = %v, want %v", result.Polymorphism.PropertyName, got, want)
+	}
+
+	// A variant can be picked by discriminator value, reference or schema name
+	for variant, property := range map[string]string{
+		"kitty":                        "lives",
+		"lizard":                       "scales",
+		"./reptiles.yaml#/Lizard":      "scales",
+		"Dog":                          "barks",
+		"#/components/schemas/Hamster": "wheel",
+	} {
+		var selected struct {
+			Variant struct {
+				Schema struct {
+					Properties map[string]interface{} `json:"properties"`
+				} `json:"schema"`
+			} `json:"variant"`
+		}
+		callToolJSON(t, oas.showSchemaHandler, map[string]interface{}{"ref": "#/components/schemas/Pet", "variant": variant}, &selected)
+		if _, exists := selected.Variant.Schema.Properties[property]; !exists {
+			t.Errorf("variant %s has properties %v, want %s", variant, selected.Variant.Schema.Properties, property)
+		}
+	}
+
+	text, isError := callTool(t, oas.showSchemaHandler, map[string]interface{}{"ref": "#/components/schemas/Pet", "variant": "Snake"})
+	if !isError || !strings.Contains(text, "Variant not found for schema Pet: Snake") {
+		t.Fatalf("unknown variant: %s", text)
+	}
+}
@@ -0,0 +1,320 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+
+	// BM25 tuning parameters
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// searchDocument is a single searchable entry in the index
+type searchDocument struct {
+	kind   string
+	info   map[string]interface{}
+	terms  map[string]int
+	length int
+}
+
+// searchIndex is a BM25 index over operations, schemas, parameters and tags
+type searchIndex struct {
+	documents     []*searchDocument
+	docFrequency  map[string]int
+	averageLength float64
+}
+
+// buildSearchIndex indexes the spec. Identifying fields such as paths, operation IDs and names are
+// weighted higher than free-text descriptions.
+func buildSearchIndex(spec *openapi3.T) *searchIndex {
+	index := &searchIndex{
+		docFrequency: map[string]int{},
+	}
+
+	type paramUsage struct {
+		param      *openapi3.Parameter
+		operations []string
+	}
+	params := map[string]*paramUsage{}
+
+	if spec.Paths != nil {
+		for path, pathItem := range spec.Paths.Map() {
+			for method, operation := range pathItem.Operations() {
+				doc := newSearchDocument("operation", map[string]interface{}{
+					"path":    path,
+					"method":  method,
+					"summary": operation.Summary,
+				})
+				if operation.OperationID != "" {
+					doc.info["operationId"] = operation.OperationID
+				}
+				doc.add(3, path, operation.OperationID)
+				doc.add(2, operation.Summary)
+				doc.add(2, operation.Tags...)
+				doc.add(1, operation.Description)
+
+				for _, declared := range effectiveParameters(pathItem, operation) {
+					param := declared.Ref.Value
+					doc.add(1, param.Name)
+
+					key := param.In + ":" + param.Name
+					if params[key] == nil {
+						params[key] = &paramUsage{param: param}
+					}
+					params[key].operations = append(params[key].operations, method+" "+path)
+				}
+
+				if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+					for _, mediaType := range operation.RequestBody.Value.Content {
+						if mediaType.Schema != nil && mediaType.Schema.Value != nil {
+							doc.add(1, propertyNames(mediaType.Schema.Value)...)
+						}
+					}
+				}
+
+				index.documents = append(index.documents, doc)
+			}
+		}
+	}
+
+	for _, usage := range params {
+		sort.Strings(usage.operations)
+		doc := newSearchDocument("parameter", map[string]interface{}{
+			"name":        usage.param.Name,
+			"in":          usage.param.In,
+			"usedBy":      len(usage.operations),
+			"operations":  usage.operations[:min(len(usage.operations), 5)],
+			"description": usage.param.Description,
+		})
+		doc.add(3, usage.param.Name)
+		doc.add(1, usage.param.Description)
+		index.documents = append(index.documents, doc)
+	}
+
+	if spec.Components != nil {
+		for name, schemaRef := range spec.Components.Schemas {
+			doc := newSearchDocument("schema", map[string]interface{}{
+				"name": name,
+				"ref":  "#/components/schemas/" + name,
+			})
+			doc.add(3, name)
+			if schemaRef != nil && schemaRef.Value != nil {
+				if schemaRef.Value.Description != "" {
+					doc.info["description"] = schemaRef.Value.Description
+				}
+				doc.add(1, schemaRef.Value.Title, schemaRef.Value.Description)
+				doc.add(2, propertyNames(schemaRef.Value)...)
+			}
+			index.documents = append(index.documents, doc)
+		}
+	}
+
+	for _, tag := range spec.Tags {
+		doc := newSearchDocument("tag", map[string]interface{}{
+			"name":        tag.Name,
+			"description": tag.Description,
+		})
+		doc.add(3, tag.Name)
+		doc.add(1, tag.Description)
+		index.documents = append(index.documents, doc)
+	}
+
+	totalLength := 0
+	for _, doc := range index.documents {
+		totalLength += doc.length
+		for term := range doc.terms {
+			index.docFrequency[term]++
+		}
+	}
+	if len(index.documents) > 0 {
+		index.averageLength = float64(totalLength) / float64(len(index.documents))
+	}
+
+	return index
+}
+
+func newSearchDocument(kind string, info map[string]interface{}) *searchDocument {
+	return &searchDocument{
+		kind:  kind,
+		info:  info,
+		terms: map[string]int{},
+	}
+}
+
+// add indexes the text of one or more fields, counting every term weight times
+func (doc *searchDocument) add(weight int, texts ...string) {
+	for _, text := range texts {
+		for _, term := range tokenize(text) {
+			doc.terms[term] += weight
+			doc.length += weight
+		}
+	}
+}
+
+// search ranks documents against the query, optionally restricted to one kind
+func (index *searchIndex) search(query, kind string, limit int) []map[string]interface{} {
+	queryTerms := tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	type scoredDocument struct {
+		doc   *searchDocument
+		score float64
+	}
+	scored := []scoredDocument{}
+	documentCount := float64(len(index.documents))
+
+	for _, doc := range index.documents {
+		if kind != "" && doc.kind != kind {
+			continue
+		}
+
+		score := 0.0
+		for _, term := range queryTerms {
+			frequency := float64(doc.terms[term])
+			if frequency == 0 {
+				continue
+			}
+			df := float64(index.docFrequency[term])
+			idf := math.Log(1 + (documentCount-df+0.5)/(df+0.5))
+			norm := 1 - bm25B + bm25B*float64(doc.length)/index.averageLength
+			score += idf * frequency * (bm25K1 + 1) / (frequency + bm25K1*norm)
+		}
+		if score > 0 {
+			scored = append(scored, scoredDocument{doc: doc, score: score})
+		}
+	}
+
+	// Ties are broken by kind and identifying fields so results are stable between calls
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		if scored[i].doc.kind != scored[j].doc.kind {
+			return scored[i].doc.kind < scored[j].doc.kind
+		}
+		return fmt.Sprint(scored[i].doc.info["path"], scored[i].doc.info["method"], scored[i].doc.info["name"], scored[i].doc.info["in"]) <
+			fmt.Sprint(scored[j].doc.info["path"], scored[j].doc.info["method"], scored[j].doc.info["name"], scored[j].doc.info["in"])
+	})
+
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	results := make([]map[string]interface{}, 0, len(scored))
+	for _, entry := range scored {
+		result := map[string]interface{}{
+			"kind":  entry.doc.kind,
+			"score": math.Round(entry.score*1000) / 1000,
+		}
+		for key, value := range entry.doc.info {
+			if value != "" {
+				result[key] = value
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// tokenize splits text into lowercase terms, breaking camelCase and snake_case identifiers into
+// their parts while also keeping the full identifier
+func tokenize(text string) []string {
+	terms := []string{}
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+
+	for _, word := range words {
+		parts := splitIdentifier(word)
+		if len(parts) > 1 {
+			terms = append(terms, strings.ToLower(strings.ReplaceAll(word, "_", "")))
+		}
+		for _, part := range parts {
+			terms = append(terms, strings.ToLower(part))
+		}
+	}
+	return terms
+}
+
+func splitIdentifier(word string) []string {
+	parts := []string{}
+	current := []rune{}
+	runes := []rune(word)
+
+	for i, r := range runes {
+		if r == '_' {
+			if len(current) > 0 {
+				parts = append(parts, string(current))
+				current = current[:0]
+			}
+			continue
+		}
+		// Split before an upper-case letter that follows a lower-case one, or that starts a new
+		// word after an acronym (e.g. "HTTPServer" -> "HTTP", "Server")
+		if unicode.IsUpper(r) && len(current) > 0 {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				parts = append(parts, string(current))
+				current = current[:0]
+			}
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		parts = append(parts, string(current))
+	}
+	return parts
+}
+
+// propertyNames returns the top-level property names of a schema, including those from allOf
+func propertyNames(schema *openapi3.Schema) []string {
+	merged := mergeAllOf(&openapi3.SchemaRef{Value: schema}).Value
+	names := make([]string, 0, len(merged.Properties))
+	for name := range merged.Properties {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (oas *OpenAPIServer) searchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := request.RequireString("query")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	kind := request.GetString("kind", "")
+	switch kind {
+	case "", "operation", "schema", "parameter", "tag":
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid kind: %s. Expected one of operation, schema, parameter, tag", kind)), nil
+	}
+
+	limit := request.GetInt("limit", DefaultSearchLimit)
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	} else if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	results := oas.index.search(query, kind, limit)
+	if len(results) == 0 {
+		return mcp.NewToolResultText(fmt.Sprintf("No results found for: %s", query)), nil
+	}
+
+	return JSONResponse(results)
+}
@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitIdentifier(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"getUserById", []string{"get", "User", "By", "Id"}},
+		{"user_account_id", []string{"user", "account", "id"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"oauth2Token", []string{"oauth2", "Token"}},
+		{"__private__", []string{"private"}},
+		{"users", []string{"users"}},
+	}
+	for _, tt := range tests {
+		if got := splitIdentifier(tt.word); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitIdentifier(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		// Identifiers keep their full form next to their parts
+		{"listPetOrders", []string{"listpetorders", "list", "pet", "orders"}},
+		{"pet_store", []string{"petstore", "pet", "store"}},
+		{"/users/{userId}", []string{"users", "userid", "user", "id"}},
+		{"Returns the pets.", []string{"returns", "the", "pets"}},
+	}
+	for _, tt := range tests {
+		if got := tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+const searchSpec = `
+openapi: 3.0.3
+info: {title: search, version: "1"}
+tags:
+  - {name: payments, description: Charges and refund handling}
+paths:
+  /payments/{id}/reversal:
+    post:
+      operationId: createRefund
+      summary: Reverse a payment
+      tags: [payments]
+      responses: {"201": {description: created}}
+  /payments:
+    get:
+      operationId: listPayments
+      summary: List payments
+      description: Payments include the amount of any refund issued for them.
+      tags: [payments]
+      responses: {"200": {description: ok}}
+components:
+  schemas:
+    Refund:
+      type: object
+      properties:
+        amount: {type: number}
+`
+
+type searchResult struct {
+	Kind        string `json:"kind"`
+	OperationID string `json:"operationId"`
+	Name        string `json:"name"`
+}
+
+func searchTestSpec(t *testing.T, oas *OpenAPIServer, args map[string]interface{}) []searchResult {
+	t.Helper()
+	var results []searchResult
+	callToolJSON(t, oas.searchHandler, args, &results)
+	return results
+}
+
+func TestSearchRanksOperationIDOverDescription(t *testing.T) {
+	oas := loadTestSpec(t, searchSpec)
+
+	results := searchTestSpec(t, oas, map[string]interface{}{"query": "refund", "kind": "operation"})
+	order := []string{}
+	for _, result := range results {
+		order = append(order, result.OperationID)
+	}
+	if want := []string{"createRefund", "listPayments"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("order = %v, want the operationId hit before the description hit %v", order, want)
+	}
+}
+
+func TestSearchKindAndLimit(t *testing.T) {
+	oas := loadTestSpec(t, searchSpec)
+
+	kinds := map[string]bool{}
+	for _, result := range searchTestSpec(t, oas, map[string]interface{}{"query": "refund"}) {
+		kinds[result.Kind] = true
+	}
+	if want := map[string]bool{"operation": true, "schema": true, "tag": true}; !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("kinds = %v, want %v", kinds, want)
+	}
+
+	results := searchTestSpec(t, oas, map[string]interface{}{"query": "refund", "kind": "schema"})
+	if len(results) != 1 || results[0].Kind != "schema" || results[0].Name != "Refund" {
+		t.Fatalf("results = %+v, want only the Refund schema", results)
+	}
+
+	if results := searchTestSpec(t, oas, map[string]interface{}{"query": "refund", "limit": float64(2)}); len(results) != 2 {
+		t.Fatalf("got %d results, want the limit of 2", len(results))
+	}
+
+	if text, isError := callTool(t, oas.searchHandler, map[string]interface{}{"query": "refund", "kind": "webhook"}); !isError {
+		t.Fatalf("an unknown kind was accepted: %s", text)
+	}
+}
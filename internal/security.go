@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// effectiveSecurity returns the security requirements that apply to an operation and whether they
+// come from the operation itself or the global spec default. An empty list means no authentication.
+func effectiveSecurity(spec *openapi3.T, operation *openapi3.Operation) (openapi3.SecurityRequirements, string) {
+	if operation.Security != nil {
+		return *operation.Security, "operation"
+	}
+	return spec.Security, "global"
+}
+
+// securityToMap describes security requirements as a list of alternatives, where every scheme
+// inside one alternative must be satisfied together. An empty alternative makes authentication
+// optional.
+func (oas *OpenAPIServer) securityToMap(requirements openapi3.SecurityRequirements, source string) map[string]interface{} {
+	alternatives := []map[string]interface{}{}
+	required := len(requirements) > 0
+	for _, requirement := range requirements {
+		if len(requirement) == 0 {
+			required = false
+		}
+		names := make([]string, 0, len(requirement))
+		for name := range requirement {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		schemes := []map[string]interface{}{}
+		for _, name := range names {
+			schemeInfo := map[string]interface{}{
+				"name": name,
+			}
+			if len(requirement[name]) > 0 {
+				schemeInfo["scopes"] = requirement[name]
+			}
+			if scheme := oas.securityScheme(name); scheme != nil {
+				for key, value := range securitySchemeToMap(scheme, false) {
+					schemeInfo[key] = value
+				}
+			}
+			schemes = append(schemes, schemeInfo)
+		}
+		alternatives = append(alternatives, map[string]interface{}{
+			"schemes": schemes,
+		})
+	}
+
+	return map[string]interface{}{
+		"source":       source,
+		"required":     required,
+		"alternatives": alternatives,
+	}
+}
+
+func (oas *OpenAPIServer) securityScheme(name string) *openapi3.SecurityScheme {
+	if oas.spec.Components == nil {
+		return nil
+	}
+	schemeRef := oas.spec.Components.SecuritySchemes[name]
+	if schemeRef == nil {
+		return nil
+	}
+	return schemeRef.Value
+}
+
+// securitySchemeToMap describes how to authenticate with a scheme. The detailed form includes OAuth
+// flows with their URLs and scopes.
+func securitySchemeToMap(scheme *openapi3.SecurityScheme, detailed bool) map[string]interface{} {
+	info := map[string]interface{}{
+		"type": scheme.Type,
+	}
+	if scheme.Scheme != "" {
+		info["scheme"] = scheme.Scheme
+	}
+	if scheme.BearerFormat != "" {
+		info["bearerFormat"] = scheme.BearerFormat
+	}
+	if scheme.In != "" {
+		info["in"] = scheme.In
+	}
+	if scheme.Name != "" {
+		info["parameterName"] = scheme.Name
+	}
+
+	if !detailed {
+		return info
+	}
+
+	if scheme.Description != "" {
+		info["description"] = scheme.Description
+	}
+	if scheme.OpenIdConnectUrl != "" {
+		info["openIdConnectUrl"] = scheme.OpenIdConnectUrl
+	}
+	if scheme.Flows != nil {
+		flows := map[string]interface{}{}
+		for flowName, flow := range map[string]*openapi3.OAuthFlow{
+			"implicit":          scheme.Flows.Implicit,
+			"password":          scheme.Flows.Password,
+			"clientCredentials": scheme.Flows.ClientCredentials,
+			"authorizationCode": scheme.Flows.AuthorizationCode,
+		} {
+			if flow == nil {
+				continue
+			}
+			flowInfo := map[string]interface{}{
+				"scopes": flow.Scopes,
+			}
+			if flow.AuthorizationURL != "" {
+				flowInfo["authorizationUrl"] = flow.AuthorizationURL
+			}
+			if flow.TokenURL != "" {
+				flowInfo["tokenUrl"] = flow.TokenURL
+			}
+			if flow.RefreshURL != "" {
+				flowInfo["refreshUrl"] = flow.RefreshURL
+			}
+			flows[flowName] = flowInfo
+		}
+		info["flows"] = flows
+	}
+
+	return info
+}
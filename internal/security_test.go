@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShowEndpointSecurity(t *testing.T) {
+	oas := loadTestSpec(t, `
+openapi: 3.0.3
+info: {title: security, version: "1"}
+security: [{apiKey: []}]
+paths:
+  /inherited:
+    get: {responses: {"200": {description: ok}}}
+  /overridden:
+    get:
+      security: [{oauth: [pets:read, pets:write]}, {apiKey: [], basic: []}]
+      responses: {"200": {description: ok}}
+  /public:
+    get:
+      security: []
+      responses: {"200": {description: ok}}
+  /optional:
+    get:
+      security: [{}, {basic: []}]
+      responses: {"200": {description: ok}}
+components:
+  securitySchemes:
+    apiKey: {type: apiKey, in: header, name: X-API-Key}
+    basic: {type: http, scheme: basic}
+    oauth:
+      type: oauth2
+      flows:
+        clientCredentials:
+          tokenUrl: https://auth.example.com/token
+          scopes: {pets:read: read, pets:write: write}
+`)
+
+	type scheme struct {
+		Name   string   `json:"name"`
+		Type   string   `json:"type"`
+		Scopes []string `json:"scopes"`
+	}
+	type security struct {
+		Source       string `json:"source"`
+		Required     bool   `json:"required"`
+		Alternatives []struct {
+			Schemes []scheme `json:"schemes"`
+		} `json:"alternatives"`
+	}
+	tests := []struct {
+		path         string
+		source       string
+		required     bool
+		alternatives [][]scheme
+	}{
+		{"/inherited", "global", true, [][]scheme{{{Name: "apiKey", Type: "apiKey"}}}},
+		{"/overridden", "operation", true, [][]scheme{
+			{{Name: "oauth", Type: "oauth2", Scopes: []string{"pets:read", "pets:write"}}},
+			{{Name: "apiKey", Type: "apiKey"}, {Name: "basic", Type: "http"}},
+		}},
+		{"/public", "operation", false, [][]scheme{}},
+		{"/optional", "operation", false, [][]scheme{{}, {{Name: "basic", Type: "http"}}}},
+	}
+
+	for _, tt := range tests {
+		var endpoint struct {
+			Security security `json:"security"`
+		}
+		callToolJSON(t, oas.showEndpointHandler, map[string]interface{}{"path": tt.path, "method": "get"}, &endpoint)
+		got := endpoint.Security
+		alternatives := [][]scheme{}
+		for _, alternative := range got.Alternatives {
+			alternatives = append(alternatives, alternative.Schemes)
+		}
+		if got.Source != tt.source || got.Required != tt.required {
+			t.Errorf("%s: source %s, required %v, want %s, %v", tt.path, got.Source, got.Required, tt.source, tt.required)
+		}
+		if !reflect.DeepEqual(alternatives, tt.alternatives) {
+			t.Errorf("%s: alternatives %+v, want %+v", tt.path, alternatives, tt.alternatives)
+		}
+	}
+}
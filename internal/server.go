@@ -1,15 +1,24 @@
 package internal
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"log"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers/legacy/pathpattern"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/oasdiff/yaml"
 )
 
 const (
@@ -20,44 +29,370 @@ const (
 )
 
 type OpenAPIServer struct {
+	// mu guards spec, index and the change markers, which are swapped when the spec is reloaded
+	mu         sync.RWMutex
 	spec       *openapi3.T
 	specSource string // URL or file path
-	cache      *Cache
+	// specHash covers the spec and every external document it references
+	specHash string
+	// digests are the content hashes of the documents the spec was loaded from, by document
+	digests map[string]string
+	// modTimes are the modification times of the documents the spec was loaded from. Remote
+	// documents have a zero time.
+	modTimes map[string]time.Time
+	// originalVersion is the version of the document as published, before any conversion
+	originalVersion string
+	name            string
+	cache           *Cache
+	index           *searchIndex
+	// router matches concrete URLs to operations
+	router *pathpattern.Node
+	// webhooks are the top-level OpenAPI 3.1 webhooks, keyed by name
+	webhooks map[string]*openapi3.PathItem
+	// issues are the validation problems found when the spec was loaded
+	issues []ValidationIssue
+
+	categoryStrategy CategoryStrategy
+	// strictValidation rejects specs with validation errors instead of serving them
+	strictValidation bool
+	// calls configures the call_endpoint tool
+	calls CallConfig
 }
 
 func NewOpenAPIServer(specSource string, cacheDir string) *OpenAPIServer {
+	cache := NewCache(cacheDir, DefaultCacheTTL)
+	cache.maxStale = GetCacheMaxStale()
+	cache.offline = GetOfflineMode()
+
 	return &OpenAPIServer{
 		specSource: specSource,
-		cache:      NewCache(cacheDir, DefaultCacheTTL),
+		name:       DefaultSpecName,
+		cache:      cache,
+
+		categoryStrategy: DefaultCategoryStrategy(),
+		strictValidation: GetStrictValidation(),
+		calls:            DefaultCallConfig(),
 	}
 }
 
 func (oas *OpenAPIServer) LoadSpec() error {
-	var data []byte
-	var err error
+	data, modTime, err := oas.readSpec()
+	if err != nil {
+		return fmt.Errorf("failed to load spec: %w", err)
+	}
+
+	loaded, err := oas.parseSpec(data, modTime)
+	if err != nil {
+		return err
+	}
+	oas.applySpec(loaded)
+	return nil
+}
+
+// ReloadSpec re-reads the spec and swaps it in if its content or that of a document it references
+// changed. Remote specs are only downloaded again once their cache entry expires; local files are
+// only read when one of them was modified. Either is only parsed again when the content of one of
+// its documents differs from what was loaded. The change markers are only updated once the new
+// version loaded, so a broken edit is retried on the next check.
+func (oas *OpenAPIServer) ReloadSpec() (bool, error) {
+	oas.mu.RLock()
+	unchanged := documentsUnchanged(oas.modTimes)
+	oas.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, modTime, err := oas.readSpec()
+	if err != nil {
+		return false, fmt.Errorf("failed to load spec: %w", err)
+	}
+
+	// Remote documents come back from the cache unchanged until they expire, so the spec is only
+	// parsed again once the content of one of its documents changed
+	oas.mu.RLock()
+	digests := oas.digests
+	oas.mu.RUnlock()
+	if modTimes, unchanged := oas.documentsMatch(data, modTime, digests); unchanged {
+		oas.mu.Lock()
+		oas.modTimes = modTimes
+		oas.mu.Unlock()
+		return false, nil
+	}
+
+	loaded, err := oas.parseSpec(data, modTime)
+	if err != nil {
+		return false, err
+	}
+
+	oas.mu.Lock()
+	unchanged = loaded.hash == oas.specHash
+	if unchanged {
+		// Touched without changing, the new modification times avoid parsing it again
+		oas.modTimes = loaded.modTimes
+	}
+	oas.mu.Unlock()
+	if unchanged {
+		return false, nil
+	}
+
+	oas.applySpec(loaded)
+	return true, nil
+}
+
+// documentsUnchanged reports whether every document is a local file that was not modified since
+// it was loaded. Remote documents are left to the cache to decide.
+func documentsUnchanged(modTimes map[string]time.Time) bool {
+	if len(modTimes) == 0 {
+		return false
+	}
+	for path, modTime := range modTimes {
+		if modTime.IsZero() {
+			return false
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// documentsMatch reports whether the spec document and every document it references still have
+// the content they were loaded with, returning their current modification times if so
+func (oas *OpenAPIServer) documentsMatch(data []byte, modTime time.Time, digests map[string]string) (map[string]time.Time, bool) {
+	root, err := oas.rootDocument()
+	if err != nil || len(digests) == 0 || digests[root] != digest(data) {
+		return nil, false
+	}
+
+	modTimes := map[string]time.Time{root: modTime}
+	for document, want := range digests {
+		if document == root {
+			continue
+		}
+		var content []byte
+		if isURL(document) {
+			content, err = oas.cache.LoadFromURL(document)
+			modTimes[document] = time.Time{}
+		} else {
+			var info os.FileInfo
+			if info, err = os.Stat(document); err == nil {
+				content, err = os.ReadFile(document)
+				modTimes[document] = info.ModTime()
+			}
+		}
+		if err != nil || digest(content) != want {
+			return nil, false
+		}
+	}
+	return modTimes, true
+}
 
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readSpec returns the spec document and, for local files, its modification time
+func (oas *OpenAPIServer) readSpec() ([]byte, time.Time, error) {
 	// Check if source is a URL
-	if strings.HasPrefix(oas.specSource, "http://") || strings.HasPrefix(oas.specSource, "https://") {
-		data, err = oas.cache.LoadFromURL(oas.specSource)
-	} else {
-		// Load from file
-		data, err = os.ReadFile(oas.specSource)
+	if isURL(oas.specSource) {
+		data, err := oas.cache.LoadFromURL(oas.specSource)
+		return data, time.Time{}, err
 	}
 
+	// The modification time is taken first so a write during the read is seen on the next check
+	info, err := os.Stat(oas.specSource)
 	if err != nil {
-		return fmt.Errorf("failed to load spec: %w", err)
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(oas.specSource)
+	if err != nil {
+		return nil, time.Time{}, err
 	}
+	return data, info.ModTime(), nil
+}
+
+// loadedSpec is a parsed spec with everything derived from it, ready to be swapped in
+type loadedSpec struct {
+	spec            *openapi3.T
+	originalVersion string
+	index           *searchIndex
+	router          *pathpattern.Node
+	webhooks        map[string]*openapi3.PathItem
+	issues          []ValidationIssue
+	hash            string
+	modTimes        map[string]time.Time
+	digests         map[string]string
+}
 
+// parseSpec parses the spec and the documents it references. It runs without the lock so tool
+// calls keep being served from the previous spec in the meantime.
+func (oas *OpenAPIServer) parseSpec(data []byte, modTime time.Time) (*loadedSpec, error) {
+	location, err := oas.specLocation()
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec location: %w", err)
+	}
+
+	root, err := oas.rootDocument()
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec location: %w", err)
+	}
+
+	// Every document is hashed and, when local, has its modification time recorded so an edit to
+	// any of them is picked up by ReloadSpec
+	hash := sha256.New()
+	hash.Write(data)
+	modTimes := map[string]time.Time{root: modTime}
+	digests := map[string]string{root: digest(data)}
+
+	// External documents are read relative to the spec location, remote ones through the cache.
+	// The refs in every document are recorded so an unresolvable ref can be traced back to the root.
+	trail := &refTrail{root: location.String()}
+	trail.addDocument(location, data)
 	loader := openapi3.NewLoader()
 	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, uri *url.URL) ([]byte, error) {
+		var document []byte
+		var err error
+		switch uri.Scheme {
+		case "http", "https":
+			document, err = oas.cache.LoadFromURL(uri.String())
+			modTimes[uri.String()] = time.Time{}
+			digests[uri.String()] = digest(document)
+		case "", "file":
+			// A remote spec must not read local files, its relative refs resolve against its URL
+			if isURL(oas.specSource) {
+				err = fmt.Errorf("local file reference %s is not allowed in a spec loaded from a URL", uri)
+				break
+			}
+			path := filepath.FromSlash(uri.Path)
+			var info os.FileInfo
+			if info, err = os.Stat(path); err == nil {
+				document, err = os.ReadFile(path)
+				modTimes[path] = info.ModTime()
+				digests[path] = digest(document)
+			}
+		default:
+			err = fmt.Errorf("unsupported scheme in external reference: %s", uri)
+		}
+		if err != nil {
+			trail.failed = uri.String()
+			return nil, err
+		}
+		trail.addDocument(uri, document)
+		fmt.Fprintf(hash, "\n%s\n", uri)
+		hash.Write(document)
+		return document, nil
+	}
 
-	spec, err := loader.LoadFromData(data)
+	// Swagger 2.0 documents are converted so every tool works on OpenAPI 3
+	var version specVersion
+	if err := yaml.Unmarshal(data, &version); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+
+	var spec *openapi3.T
+	var webhookData []byte
+	if strings.HasPrefix(version.Swagger, "2.") {
+		var spec2 openapi2.T
+		if err := yaml.Unmarshal(data, &spec2); err != nil {
+			return nil, fmt.Errorf("failed to parse Swagger spec: %w", err)
+		}
+		spec, err = openapi2conv.ToV3WithLoader(&spec2, loader, location)
+	} else {
+		// OpenAPI 3.1 documents are rewritten into the 3.0 model the loader is built on
+		document := data
+		if strings.HasPrefix(version.OpenAPI, "3.1") {
+			if document, webhookData, err = normalizeOpenAPI31(data); err != nil {
+				return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+			}
+		}
+		spec, err = loader.LoadFromDataWithPath(document, location)
+	}
+	var webhooks map[string]*openapi3.PathItem
+	if err == nil {
+		webhooks, err = loadWebhooks(loader, spec, webhookData, location)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+		if chain := trail.explain(err); chain != "" {
+			return nil, fmt.Errorf("failed to parse OpenAPI spec: %w (ref chain: %s)", err, chain)
+		}
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
 	}
 
-	oas.spec = spec
-	return nil
+	// Validation problems are reported through validate_spec, and only block loading in strict mode
+	issues := validateSpec(spec, webhooks, strings.HasPrefix(version.OpenAPI, "3.1"))
+	if errors, warnings := countIssues(issues); errors > 0 && oas.strictValidation {
+		return nil, fmt.Errorf("spec has %d validation errors, the first at %s: %s", errors, firstError(issues).Pointer, firstError(issues).Message)
+	} else if errors > 0 || warnings > 0 {
+		log.Printf("Spec %s has %d validation errors and %d warnings, see validate_spec", oas.name, errors, warnings)
+	}
+
+	return &loadedSpec{
+		spec:            spec,
+		originalVersion: version.String(),
+		index:           buildSearchIndex(spec),
+		router:          buildRouter(spec),
+		webhooks:        webhooks,
+		issues:          issues,
+		hash:            hex.EncodeToString(hash.Sum(nil)),
+		modTimes:        modTimes,
+		digests:         digests,
+	}, nil
+}
+
+// applySpec swaps in a parsed spec together with its change markers
+func (oas *OpenAPIServer) applySpec(loaded *loadedSpec) {
+	oas.mu.Lock()
+	defer oas.mu.Unlock()
+
+	oas.spec = loaded.spec
+	oas.index = loaded.index
+	oas.router = loaded.router
+	oas.webhooks = loaded.webhooks
+	oas.issues = loaded.issues
+	oas.specHash = loaded.hash
+	oas.modTimes = loaded.modTimes
+	oas.digests = loaded.digests
+	oas.originalVersion = loaded.originalVersion
+}
+
+// specVersion holds the version markers of a Swagger 2.0 or OpenAPI 3 document
+type specVersion struct {
+	Swagger string `json:"swagger"`
+	OpenAPI string `json:"openapi"`
+}
+
+func (v specVersion) String() string {
+	if v.Swagger != "" {
+		return "swagger " + v.Swagger
+	}
+	return "openapi " + v.OpenAPI
+}
+
+// specLocation is the base location that relative external references are resolved against
+func (oas *OpenAPIServer) specLocation() (*url.URL, error) {
+	if isURL(oas.specSource) {
+		return url.Parse(oas.specSource)
+	}
+	absPath, err := filepath.Abs(oas.specSource)
+	if err != nil {
+		return nil, err
+	}
+	return &url.URL{Path: filepath.ToSlash(absPath)}, nil
+}
+
+// rootDocument is the spec document as keyed in the modification times and digests
+func (oas *OpenAPIServer) rootDocument() (string, error) {
+	if isURL(oas.specSource) {
+		return oas.specSource, nil
+	}
+	return filepath.Abs(oas.specSource)
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
 }
 
 func GetCacheDir() string {
@@ -70,26 +405,71 @@ func GetCacheDir() string {
 }
 
 // CreateMCPServerWithTools creates an MCP server instance with all tools registered
-func CreateMCPServerWithTools(oas *OpenAPIServer) *server.MCPServer {
+func CreateMCPServerWithTools(registry *SpecRegistry) *server.MCPServer {
 	s := server.NewMCPServer(
 		"OpenAPI MCP Server",
 		"1.0.0",
 		server.WithToolCapabilities(true),
 	)
 
+	oas := registry.Default()
+	multiSpec := len(registry.Servers()) > 1
+
+	// addSpecTool routes each call to the spec selected by the optional "spec" argument
+	addSpecTool := func(tool mcp.Tool, handler server.ToolHandlerFunc) {
+		if multiSpec {
+			mcp.WithString("spec",
+				mcp.Description(fmt.Sprintf("The name of the spec to query, as returned by list_specs (default: %s)", oas.name)),
+			)(&tool)
+		}
+		s.AddTool(tool, handler)
+	}
+	addTool := func(tool mcp.Tool, handler specHandler) {
+		addSpecTool(tool, registry.dispatch(handler))
+	}
+
 	// Register all tools
+	if multiSpec {
+		listSpecsTool := mcp.NewTool("list_specs",
+			mcp.WithDescription("List the OpenAPI specs served by this server. Pass a spec name as the spec argument of other tools"),
+		)
+		s.AddTool(listSpecsTool, registry.listSpecsHandler)
+	}
+
+	searchTool := mcp.NewTool("search",
+		mcp.WithDescription("Search operations, schemas, parameters and tags by relevance. Prefer this over browsing categories when looking for something specific"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("Free-text query matched against paths, operation IDs, summaries, descriptions, tags and property names"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Restrict results to one kind"),
+			mcp.Enum("operation", "schema", "parameter", "tag"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of results (default %d, max %d)", DefaultSearchLimit, MaxSearchLimit)),
+		),
+	)
+	addTool(searchTool, (*OpenAPIServer).searchHandler)
+
 	listCategoriesTool := mcp.NewTool("list_categories",
-		mcp.WithDescription("List all categories based on the first path segment of endpoints. Always call this before querying deeper!"),
+		mcp.WithDescription(fmt.Sprintf("List all categories based on %s. Always call this before querying deeper!", oas.categoryStrategy.Description())),
 	)
-	s.AddTool(listCategoriesTool, oas.listCategoriesHandler)
+	addTool(listCategoriesTool, (*OpenAPIServer).listCategoriesHandler)
 
 	listEndpointsTool := mcp.NewTool("list_endpoints",
-		mcp.WithDescription("List endpoints, filtered by category (based on first path segment). Always check the list of categories first!"),
+		mcp.WithDescription(fmt.Sprintf("List endpoints, filtered by category (based on %s). Always check the list of categories first!", oas.categoryStrategy.Description())),
 		mcp.WithString("category",
-			mcp.Description("The category (first path segment) to filter endpoints by."),
+			mcp.Description("The category, as returned by list_categories, to filter endpoints by."),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of endpoints per page (default: all). The result holds the endpoints in items, the total count and next_cursor while more endpoints remain."),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("The next_cursor value from a previous page"),
 		),
 	)
-	s.AddTool(listEndpointsTool, oas.listEndpointsHandler)
+	addTool(listEndpointsTool, (*OpenAPIServer).listEndpointsHandler)
 
 	showEndpointTool := mcp.NewTool("show_endpoint",
 		mcp.WithDescription("Show detailed information about a specific endpoint including types"),
@@ -101,22 +481,232 @@ func CreateMCPServerWithTools(oas *OpenAPIServer) *server.MCPServer {
 			mcp.Required(),
 			mcp.Description("The HTTP method (GET, POST, PUT, DELETE, etc.)"),
 		),
+		mcp.WithBoolean("merged",
+			mcp.Description("Flatten allOf compositions in request/response bodies into a single effective schema"),
+		),
 	)
-	s.AddTool(showEndpointTool, oas.showEndpointHandler)
+	addTool(showEndpointTool, (*OpenAPIServer).showEndpointHandler)
 
 	getSpecInfoTool := mcp.NewTool("get_spec_info",
 		mcp.WithDescription("Get general information about the OpenAPI specification"),
 	)
-	s.AddTool(getSpecInfoTool, oas.getSpecInfoHandler)
+	addTool(getSpecInfoTool, (*OpenAPIServer).getSpecInfoHandler)
+
+	listSecuritySchemesTool := mcp.NewTool("list_security_schemes",
+		mcp.WithDescription("List the security schemes of the API with their flows, token URLs and scopes"),
+	)
+	addTool(listSecuritySchemesTool, (*OpenAPIServer).listSecuritySchemesHandler)
 
 	showSchemaTool := mcp.NewTool("show_schema",
-		mcp.WithDescription("Show details of a specific schema component by reference"),
+		mcp.WithDescription("Show details of a specific schema component by reference, including discriminator subtypes for polymorphic schemas"),
 		mcp.WithString("ref",
 			mcp.Required(),
 			mcp.Description("The schema reference (e.g., #/components/schemas/User)"),
 		),
+		mcp.WithBoolean("merged",
+			mcp.Description("Also return a view with allOf flattened into a single effective schema"),
+		),
+		mcp.WithString("variant",
+			mcp.Description("For polymorphic schemas, expand the subtype with this discriminator value or schema name"),
+		),
+	)
+	addTool(showSchemaTool, (*OpenAPIServer).showSchemaHandler)
+
+	resolveRefTool := mcp.NewTool("resolve_ref",
+		mcp.WithDescription("Resolve any JSON pointer reference into the spec (schemas, parameters, responses, request bodies, headers, security schemes, examples or external document refs used by the spec)"),
+		mcp.WithString("ref",
+			mcp.Required(),
+			mcp.Description("The reference to resolve (e.g., #/components/parameters/PageSize or common.yaml#/components/schemas/Error)"),
+		),
+	)
+	addTool(resolveRefTool, (*OpenAPIServer).resolveRefHandler)
+
+	listWebhooksTool := mcp.NewTool("list_webhooks",
+		mcp.WithDescription("List the webhooks (OpenAPI 3.1) the API calls on the client side, with their operations"),
+	)
+	addTool(listWebhooksTool, (*OpenAPIServer).listWebhooksHandler)
+
+	showWebhookTool := mcp.NewTool("show_webhook",
+		mcp.WithDescription("Show detailed information about a webhook including the payload it sends and the responses it expects"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("The webhook name, as returned by list_webhooks"),
+		),
+		mcp.WithString("method",
+			mcp.Description("The HTTP method, only needed when the webhook has more than one operation"),
+		),
+		mcp.WithBoolean("merged",
+			mcp.Description("Flatten allOf compositions in request/response bodies into a single effective schema"),
+		),
+	)
+	addTool(showWebhookTool, (*OpenAPIServer).showWebhookHandler)
+
+	validateSpecTool := mcp.NewTool("validate_spec",
+		mcp.WithDescription("Report problems in the OpenAPI specification itself, each with a JSON pointer, severity and message. Check this when the spec data looks incomplete or inconsistent"),
+		mcp.WithString("severity",
+			mcp.Description("Only return issues of this severity"),
+			mcp.Enum(SeverityError, SeverityWarning),
+		),
+	)
+	addTool(validateSpecTool, (*OpenAPIServer).validateSpecHandler)
+
+	generateExampleTool := mcp.NewTool("generate_example",
+		mcp.WithDescription("Generate an example JSON payload for an endpoint's request body or one of its responses. Uses the examples documented in the spec when present, otherwise synthesizes one from the schema"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("The path of the endpoint (e.g., /users/{id})"),
+		),
+		mcp.WithString("method",
+			mcp.Required(),
+			mcp.Description("The HTTP method (GET, POST, PUT, DELETE, etc.)"),
+		),
+		mcp.WithString("target",
+			mcp.Description("Generate the request body or a response (default: request)"),
+			mcp.Enum(exampleTargetRequest, exampleTargetResponse),
+		),
+		mcp.WithString("status",
+			mcp.Description("The response status code, e.g. 200 or default (default: the first 2XX response)"),
+		),
+		mcp.WithString("media_type",
+			mcp.Description("The media type (default: application/json, or the first one documented)"),
+		),
+		mcp.WithNumber("seed",
+			mcp.Description("Seed for the synthesized values. The same seed always gives the same example (default: 0)"),
+		),
+	)
+	addTool(generateExampleTool, (*OpenAPIServer).generateExampleHandler)
+
+	buildRequestTool := mcp.NewTool("build_request",
+		mcp.WithDescription("Build a ready-to-run curl and HTTPie command for an endpoint. Uses the spec's servers, serializes parameters by their style and adds placeholders for credentials. Missing required values are filled from examples"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("The path of the endpoint (e.g., /users/{id})"),
+		),
+		mcp.WithString("method",
+			mcp.Required(),
+			mcp.Description("The HTTP method (GET, POST, PUT, DELETE, etc.)"),
+		),
+		mcp.WithObject("parameters",
+			mcp.Description("Parameter values by name, e.g. {\"id\": 42, \"tags\": [\"a\", \"b\"]}. Path, query, header and cookie parameters are placed automatically"),
+		),
+		withAnyValue("body",
+			mcp.Description("The request body. Objects and arrays are encoded for the media type; strings are sent as-is"),
+		),
+		mcp.WithString("media_type",
+			mcp.Description("The request body media type (default: application/json, or the first one documented)"),
+		),
+		mcp.WithString("server",
+			mcp.Description("The server to use: an index into the spec's servers, one of their URLs, or an absolute base URL (default: the first server)"),
+		),
+		mcp.WithObject("server_variables",
+			mcp.Description("Values for server URL variables (default: the variable defaults)"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Extra request headers by name"),
+		),
 	)
-	s.AddTool(showSchemaTool, oas.showSchemaHandler)
+	addTool(buildRequestTool, (*OpenAPIServer).buildRequestHandler)
+
+	validateRequestTool := mcp.NewTool("validate_request",
+		mcp.WithDescription("Validate a request against an endpoint's parameters, security and request body schema. Returns every violation with a JSON pointer into the arguments and the schema rule it breaks"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("The path of the endpoint (e.g., /users/{id})"),
+		),
+		mcp.WithString("method",
+			mcp.Required(),
+			mcp.Description("The HTTP method (GET, POST, PUT, DELETE, etc.)"),
+		),
+		mcp.WithObject("parameters",
+			mcp.Description("Parameter values by name, e.g. {\"id\": 42, \"tags\": [\"a\", \"b\"]}. Path, query, header and cookie parameters are placed automatically"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Raw request headers by name, e.g. {\"Authorization\": \"Bearer abc\"}"),
+		),
+		withAnyValue("body",
+			mcp.Description("The request body. Objects and arrays are encoded for the media type; strings are sent as-is"),
+		),
+		mcp.WithString("media_type",
+			mcp.Description("The request body media type (default: application/json, or the first one documented)"),
+		),
+	)
+	addTool(validateRequestTool, (*OpenAPIServer).validateRequestHandler)
+
+	validateResponseTool := mcp.NewTool("validate_response",
+		mcp.WithDescription("Check an actual response against the documented response for its status code. Reports schema mismatches, missing required fields and headers, undocumented status codes and fields the schema does not declare"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("The path of the endpoint (e.g., /users/{id})"),
+		),
+		mcp.WithString("method",
+			mcp.Required(),
+			mcp.Description("The HTTP method (GET, POST, PUT, DELETE, etc.)"),
+		),
+		mcp.WithNumber("status",
+			mcp.Required(),
+			mcp.Description("The HTTP status code of the response"),
+		),
+		mcp.WithString("content_type",
+			mcp.Description("The response content type (default: the one documented for the status, preferring JSON)"),
+		),
+		mcp.WithObject("headers",
+			mcp.Description("Response headers by name"),
+		),
+		withAnyValue("body",
+			mcp.Description("The response body, as parsed JSON or as the raw text"),
+		),
+	)
+	addTool(validateResponseTool, (*OpenAPIServer).validateResponseHandler)
+
+	matchRequestTool := mcp.NewTool("match_request",
+		mcp.WithDescription("Find the operation serving a concrete URL, such as one from a log line. Strips the spec's server base paths and returns the path template, the path parameter values and the decoded query parameters"),
+		mcp.WithString("url",
+			mcp.Required(),
+			mcp.Description("The URL or path, e.g. https://api.example.com/v1/users/42?limit=5. A leading method as in \"GET /users/42\" is accepted"),
+		),
+		mcp.WithString("method",
+			mcp.Description("The HTTP method (default: the method leading the url, or GET)"),
+		),
+	)
+	addTool(matchRequestTool, (*OpenAPIServer).matchRequestHandler)
+
+	// Sending requests is opt-in
+	if oas.calls.Enabled {
+		callEndpointTool := mcp.NewTool("call_endpoint",
+			mcp.WithDescription(fmt.Sprintf("Send a real request to an endpoint and check the response against the spec. Credentials are injected from the %s* environment variables named by build_request. Allowed methods: %s. Requests that break the spec are not sent", credentialPrefix(oas.name), strings.Join(oas.calls.Methods, ", "))),
+			mcp.WithString("path",
+				mcp.Required(),
+				mcp.Description("The path of the endpoint (e.g., /users/{id})"),
+			),
+			mcp.WithString("method",
+				mcp.Required(),
+				mcp.Description("The HTTP method (GET, POST, PUT, DELETE, etc.)"),
+			),
+			mcp.WithObject("parameters",
+				mcp.Description("Parameter values by name, e.g. {\"id\": 42, \"tags\": [\"a\", \"b\"]}. Path, query, header and cookie parameters are placed automatically"),
+			),
+			mcp.WithObject("headers",
+				mcp.Description("Extra request headers by name"),
+			),
+			withAnyValue("body",
+				mcp.Description("The request body. Objects and arrays are encoded for the media type; strings are sent as-is"),
+			),
+			mcp.WithString("media_type",
+				mcp.Description("The request body media type (default: application/json, or the first one documented)"),
+			),
+			mcp.WithString("server",
+				mcp.Description("The server to use: an index into the spec's servers, one of their URLs, or an absolute base URL (default: the configured base URL, or the first server)"),
+			),
+			mcp.WithObject("server_variables",
+				mcp.Description("Values for server URL variables (default: the variable defaults)"),
+			),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("Prepare and check the request without sending it"),
+			),
+		)
+		// The handler releases the spec lock while waiting for the API
+		addSpecTool(callEndpointTool, registry.dispatchUnlocked((*OpenAPIServer).callEndpointHandler))
+	}
 
 	return s
 }
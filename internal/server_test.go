@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSwagger2Conversion(t *testing.T) {
+	oas := loadTestSpec(t, `
+swagger: "2.0"
+info: {title: legacy, version: "1.2"}
+host: api.example.com
+basePath: /v2
+schemes: [https]
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      produces: [application/json]
+      parameters:
+        - {name: id, in: path, required: true, type: integer}
+      responses:
+        "200":
+          description: ok
+          schema: {$ref: "#/definitions/Pet"}
+  /pets:
+    post:
+      consumes: [application/json]
+      parameters:
+        - {name: pet, in: body, required: true, schema: {$ref: "#/definitions/Pet"}}
+      responses:
+        "201": {description: created}
+definitions:
+  Pet:
+    type: object
+    properties:
+      name: {type: string}
+`)
+
+	var page endpointPage
+	callToolJSON(t, oas.listEndpointsHandler, map[string]interface{}{}, &page)
+	want := []listedEndpoint{{Path: "/pets", Method: "POST"}, {Path: "/pets/{id}", Method: "GET"}}
+	if !reflect.DeepEqual(page.Items, want) {
+		t.Fatalf("converted endpoints = %v, want %v", page.Items, want)
+	}
+
+	// Body parameters become request bodies and definitions become component schemas
+	post := oas.spec.Paths.Find("/pets").Post
+	if post.RequestBody == nil || post.RequestBody.Value.Content.Get("application/json") == nil {
+		t.Fatalf("body parameter was not converted to a request body: %+v", post.RequestBody)
+	}
+	if _, exists := oas.spec.Components.Schemas["Pet"]; !exists {
+		t.Fatal("definitions were not converted to component schemas")
+	}
+
+	var info struct {
+		OpenAPI         string              `json:"openapi"`
+		OriginalVersion string              `json:"original_version"`
+		Converted       bool                `json:"converted"`
+		Servers         []map[string]string `json:"servers"`
+	}
+	callToolJSON(t, oas.getSpecInfoHandler, map[string]interface{}{}, &info)
+	if info.OriginalVersion != "2.0" || !info.Converted {
+		t.Errorf("original_version = %q, converted = %v", info.OriginalVersion, info.Converted)
+	}
+	if !strings.HasPrefix(info.OpenAPI, "3.") {
+		t.Errorf("openapi = %q, want a 3.x version", info.OpenAPI)
+	}
+	if len(info.Servers) != 1 || info.Servers[0]["url"] != "https://api.example.com/v2" {
+		t.Errorf("servers = %v, want the one derived from host, basePath and schemes", info.Servers)
+	}
+}
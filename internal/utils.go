@@ -1,8 +1,13 @@
 package internal
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -16,11 +21,68 @@ func SortMapsByName(items []map[string]interface{}) {
 	})
 }
 
+// withAnyValue adds a tool argument accepting any JSON value, for payloads shaped by the spec
+func withAnyValue(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		schema := map[string]any{}
+		for _, opt := range opts {
+			opt(schema)
+		}
+		tool.InputSchema.Properties[name] = schema
+	}
+}
+
 // JSONResponse creates a standard MCP JSON response
 func JSONResponse(data interface{}) (*mcp.CallToolResult, error) {
-	jsonBytes, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
+	// URLs and commands are returned verbatim rather than with &, < and > escaped
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
 		return mcp.NewToolResultError("Failed to marshal response"), nil
 	}
-	return mcp.NewToolResultText(string(jsonBytes)), nil
+	return mcp.NewToolResultText(strings.TrimSuffix(buffer.String(), "\n")), nil
+}
+
+// EncodeCursor creates an opaque pagination cursor for the given offset
+func EncodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// DecodeCursor returns the offset encoded in a pagination cursor. An empty cursor starts at the beginning.
+func DecodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		var offset int
+		if _, scanErr := fmt.Sscanf(string(decoded), "offset:%d", &offset); scanErr == nil && offset >= 0 {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor: %s", cursor)
+}
+
+// Paginate returns one page of items with a next_cursor when more items remain. A non-positive
+// limit returns everything from the offset onwards.
+func Paginate(items []map[string]interface{}, offset, limit int) map[string]interface{} {
+	total := len(items)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+
+	page := map[string]interface{}{
+		"items": items[offset:end],
+		"total": total,
+	}
+	if end < total {
+		page["next_cursor"] = EncodeCursor(end)
+	}
+	return page
 }
@@ -0,0 +1,294 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// jsonSchemaKeywords are OpenAPI 3.1 keywords the 3.0 model keeps as extensions. They are allowed
+// next to the known fields when validating 3.1 documents.
+var jsonSchemaKeywords = []string{
+	"const", "examples", "$defs", "prefixItems", "contentMediaType", "contentEncoding",
+	"$schema", "$id", "$anchor", "$comment", "if", "then", "else", "dependentSchemas",
+	"dependentRequired", "unevaluatedProperties", "unevaluatedItems", "contains", "minContains",
+	"maxContains", "patternProperties", "propertyNames", "summary", "identifier",
+}
+
+// ValidationIssue is a problem found in the spec itself, located by a JSON pointer into the
+// document as loaded (after any Swagger 2.0 conversion)
+type ValidationIssue struct {
+	Pointer  string `json:"pointer"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// GetStrictValidation reports whether specs with validation errors should fail to load
+func GetStrictValidation() bool {
+	strict, _ := strconv.ParseBool(os.Getenv("OPENAPI_STRICT_VALIDATION"))
+	return strict
+}
+
+// specValidator collects every issue instead of stopping at the first one like openapi3.T.Validate
+type specValidator struct {
+	ctx    context.Context
+	spec   *openapi3.T
+	issues []ValidationIssue
+	// componentErrors are reported once at the component instead of at every operation using it
+	componentErrors []string
+	// missingOperationIDs are the operations without an operationId, reported in one warning
+	missingOperationIDs []string
+}
+
+// validateSpec checks the document part by part so each issue points at where it was found
+func validateSpec(spec *openapi3.T, webhooks map[string]*openapi3.PathItem, openapi31 bool) []ValidationIssue {
+	var opts []openapi3.ValidationOption
+	if openapi31 {
+		opts = append(opts, openapi3.AllowExtraSiblingFields(jsonSchemaKeywords...))
+	}
+	v := &specValidator{
+		ctx:    openapi3.WithValidationOptions(context.Background(), opts...),
+		spec:   spec,
+		issues: []ValidationIssue{},
+	}
+
+	if spec.Info == nil {
+		v.add("/info", SeverityError, "info is required")
+	} else if err := spec.Info.Validate(v.ctx); err != nil {
+		v.add("/info", SeverityError, err.Error())
+	}
+
+	for i, server := range spec.Servers {
+		if err := server.Validate(v.ctx); err != nil {
+			v.add(fmt.Sprintf("/servers/%d", i), SeverityError, err.Error())
+		}
+	}
+
+	if err := spec.Tags.Validate(v.ctx); err != nil {
+		v.add("/tags", SeverityError, err.Error())
+	}
+
+	v.validateComponents()
+	v.validateSecurity("/security", spec.Security)
+
+	operationIDs := map[string]string{}
+	for _, entry := range sortedOperations(spec) {
+		pointer := "/paths/" + escapePointer(entry.Path) + "/" + strings.ToLower(entry.Method)
+		v.validatePathParameters(pointer, entry)
+		v.validateOperation(pointer, entry.Operation, operationIDs)
+	}
+	for _, path := range sortedKeys(spec.Paths.Map()) {
+		if !strings.HasPrefix(path, "/") {
+			v.add("/paths/"+escapePointer(path), SeverityError, "path does not start with a forward slash (/)")
+		}
+		if err := spec.Paths.Value(path).Parameters.Validate(v.ctx); err != nil {
+			v.add("/paths/"+escapePointer(path)+"/parameters", SeverityError, err.Error())
+		}
+	}
+
+	for _, name := range sortedKeys(webhooks) {
+		for _, method := range methodOrder {
+			if operation := webhooks[name].GetOperation(method); operation != nil {
+				v.validateOperation("/webhooks/"+escapePointer(name)+"/"+strings.ToLower(method), operation, operationIDs)
+			}
+		}
+	}
+
+	v.summarizeMissingOperationIDs()
+
+	return v.issues
+}
+
+// summarizeMissingOperationIDs reports operations without an operationId in a single warning at
+// the first of them, as large specs often leave them out everywhere
+func (v *specValidator) summarizeMissingOperationIDs() {
+	switch count := len(v.missingOperationIDs); count {
+	case 0:
+	case 1:
+		v.add(v.missingOperationIDs[0], SeverityWarning, "operation has no operationId")
+	default:
+		v.add(v.missingOperationIDs[0], SeverityWarning, fmt.Sprintf("%d operations have no operationId, starting with this one", count))
+	}
+}
+
+func (v *specValidator) add(pointer, severity, message string) {
+	v.issues = append(v.issues, ValidationIssue{Pointer: pointer, Severity: severity, Message: message})
+}
+
+func (v *specValidator) validateComponents() {
+	components := v.spec.Components
+	if components == nil {
+		return
+	}
+
+	check := func(kind, name string, validate func(context.Context, ...openapi3.ValidationOption) error) {
+		if err := validate(v.ctx); err != nil {
+			v.add("/components/"+kind+"/"+escapePointer(name), SeverityError, err.Error())
+			v.componentErrors = append(v.componentErrors, err.Error())
+		}
+	}
+
+	for _, name := range sortedKeys(components.Schemas) {
+		check("schemas", name, components.Schemas[name].Validate)
+	}
+	for _, name := range sortedKeys(components.Parameters) {
+		check("parameters", name, components.Parameters[name].Validate)
+	}
+	for _, name := range sortedKeys(components.Headers) {
+		check("headers", name, components.Headers[name].Validate)
+	}
+	for _, name := range sortedKeys(components.RequestBodies) {
+		check("requestBodies", name, components.RequestBodies[name].Validate)
+	}
+	for _, name := range sortedKeys(components.Responses) {
+		check("responses", name, components.Responses[name].Validate)
+	}
+	for _, name := range sortedKeys(components.SecuritySchemes) {
+		check("securitySchemes", name, components.SecuritySchemes[name].Validate)
+	}
+	for _, name := range sortedKeys(components.Examples) {
+		check("examples", name, components.Examples[name].Validate)
+	}
+}
+
+func (v *specValidator) validateOperation(pointer string, operation *openapi3.Operation, operationIDs map[string]string) {
+	if err := operation.Validate(v.ctx); err != nil && !v.reportedAtComponent(err) {
+		v.add(pointer, SeverityError, err.Error())
+	}
+
+	if operation.OperationID == "" {
+		v.missingOperationIDs = append(v.missingOperationIDs, pointer)
+	} else if previous, exists := operationIDs[operation.OperationID]; exists {
+		v.add(pointer+"/operationId", SeverityError, fmt.Sprintf("operationId %s is also used by %s", operation.OperationID, previous))
+	} else {
+		operationIDs[operation.OperationID] = pointer
+	}
+
+	if operation.Security != nil {
+		v.validateSecurity(pointer+"/security", *operation.Security)
+	}
+
+	if operation.Responses != nil && operation.Responses.Len() > 0 {
+		success := operation.Responses.Default() != nil
+		for status := range operation.Responses.Map() {
+			if strings.HasPrefix(status, "2") {
+				success = true
+			}
+		}
+		if !success {
+			v.add(pointer+"/responses", SeverityWarning, "no success (2XX) or default response is documented")
+		}
+	}
+}
+
+// validatePathParameters checks that the path template and the declared path parameters match
+func (v *specValidator) validatePathParameters(pointer string, entry operationEntry) {
+	declared := map[string]bool{}
+	for _, param := range effectiveParameters(entry.PathItem, entry.Operation) {
+		if param.Ref.Value.In == openapi3.ParameterInPath {
+			declared[param.Ref.Value.Name] = true
+		}
+	}
+
+	templated := map[string]bool{}
+	for _, segment := range strings.Split(entry.Path, "{")[1:] {
+		name, _, _ := strings.Cut(segment, "}")
+		templated[name] = true
+	}
+
+	for _, name := range sortedKeys(templated) {
+		if !declared[name] {
+			v.add(pointer+"/parameters", SeverityError, fmt.Sprintf("path parameter %s is not declared", name))
+		}
+	}
+	for _, name := range sortedKeys(declared) {
+		if !templated[name] {
+			v.add(pointer+"/parameters", SeverityError, fmt.Sprintf("path parameter %s does not appear in the path", name))
+		}
+	}
+}
+
+// validateSecurity checks that security requirements only name declared schemes
+func (v *specValidator) validateSecurity(pointer string, requirements openapi3.SecurityRequirements) {
+	for i, requirement := range requirements {
+		for _, name := range sortedKeys(requirement) {
+			if v.spec.Components == nil || v.spec.Components.SecuritySchemes[name] == nil {
+				v.add(fmt.Sprintf("%s/%d", pointer, i), SeverityError, fmt.Sprintf("security scheme %s is not declared in components", name))
+			}
+		}
+	}
+}
+
+func (v *specValidator) reportedAtComponent(err error) bool {
+	for _, componentError := range v.componentErrors {
+		if strings.Contains(err.Error(), componentError) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// countIssues returns the number of errors and warnings
+func countIssues(issues []ValidationIssue) (errors, warnings int) {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+func firstError(issues []ValidationIssue) ValidationIssue {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return issue
+		}
+	}
+	return ValidationIssue{}
+}
+
+func (oas *OpenAPIServer) validateSpecHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	severity := request.GetString("severity", "")
+	switch severity {
+	case "", SeverityError, SeverityWarning:
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid severity: %s. Expected error or warning", severity)), nil
+	}
+
+	issues := []ValidationIssue{}
+	for _, issue := range oas.issues {
+		if severity == "" || issue.Severity == severity {
+			issues = append(issues, issue)
+		}
+	}
+
+	errors, warnings := countIssues(oas.issues)
+	return JSONResponse(map[string]interface{}{
+		"valid":    errors == 0,
+		"errors":   errors,
+		"warnings": warnings,
+		"issues":   issues,
+	})
+}
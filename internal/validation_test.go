@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateSpecSummarizesMissingOperationIDs(t *testing.T) {
+	oas := loadTestSpec(t, `
+openapi: 3.0.3
+info: {title: validation, version: "1"}
+paths:
+  /a:
+    get: {responses: {"200": {description: ok}}}
+    post: {operationId: createA, responses: {"201": {description: created}}}
+  /b:
+    get: {responses: {"200": {description: ok}}}
+    delete: {responses: {"204": {description: gone}}}
+`)
+
+	var report struct {
+		Warnings int               `json:"warnings"`
+		Issues   []ValidationIssue `json:"issues"`
+	}
+	callToolJSON(t, oas.validateSpecHandler, map[string]interface{}{"severity": SeverityWarning}, &report)
+	want := []ValidationIssue{{
+		Pointer:  "/paths/~1a/get",
+		Severity: SeverityWarning,
+		Message:  "3 operations have no operationId, starting with this one",
+	}}
+	if report.Warnings != 1 || !reflect.DeepEqual(report.Issues, want) {
+		t.Fatalf("warnings = %d, issues = %+v, want %+v", report.Warnings, report.Issues, want)
+	}
+}